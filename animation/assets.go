@@ -0,0 +1,20 @@
+package animation
+
+import (
+	"io/fs"
+	"os"
+)
+
+// embedded contains the assets when built with the "embed" tag.
+var embedded fs.FS
+
+// Assets returns the file system containing the characters.
+//
+// When built with the "embed" tag the assets are part of the binary
+// and dir is ignored, otherwise the assets are read from dir.
+func Assets(dir string) fs.FS {
+	if embedded != nil {
+		return embedded
+	}
+	return os.DirFS(dir)
+}
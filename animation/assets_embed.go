@@ -0,0 +1,11 @@
+//go:build embed
+// +build embed
+
+package animation
+
+import "embed"
+
+//go:embed */manifest.json */license.txt */export */images
+var assets embed.FS
+
+func init() { embedded = assets }
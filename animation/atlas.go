@@ -0,0 +1,88 @@
+package animation
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"path"
+
+	"github.com/adinfinit/spine-examples/atlas"
+
+	_ "image/png"
+)
+
+// UsePMA switches to the premultiplied alpha atlas when the location has one.
+func (loc *Location) UsePMA(enable bool) {
+	loc.PMA = enable && loc.PMAAtlas != ""
+}
+
+// EnablePMA switches every location that has a premultiplied alpha atlas to it.
+func EnablePMA(locs []Location) {
+	for i := range locs {
+		locs[i].UsePMA(true)
+	}
+}
+
+// AtlasPath returns the atlas selected by loc.PMA.
+func (loc *Location) AtlasPath() string {
+	if loc.PMA {
+		return loc.PMAAtlas
+	}
+	return loc.Atlas
+}
+
+// LoadAtlas reads the texture atlas of the location.
+func (loc *Location) LoadAtlas() (*atlas.Atlas, error) {
+	rd, err := loc.FS.Open(loc.AtlasPath())
+	if err != nil {
+		return nil, err
+	}
+	defer rd.Close()
+
+	result, err := atlas.Parse(rd)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", loc.AtlasPath(), err)
+	}
+	return result, nil
+}
+
+// LoadPage decodes the image of an atlas page.
+//
+// In PMA mode the texels are stored premultiplied, therefore the
+// result is an *image.RGBA that contains the file data unmodified.
+func (loc *Location) LoadPage(page *atlas.Page) (image.Image, error) {
+	pagepath := path.Join(path.Dir(loc.AtlasPath()), page.Name)
+	rd, err := loc.FS.Open(pagepath)
+	if err != nil {
+		return nil, err
+	}
+	defer rd.Close()
+
+	m, _, err := image.Decode(rd)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", pagepath, err)
+	}
+
+	if loc.PMA {
+		return premultiplied(m), nil
+	}
+	return m, nil
+}
+
+// premultiplied reinterprets the decoded, supposedly straight, image
+// data as premultiplied.
+func premultiplied(m image.Image) *image.RGBA {
+	if nrgba, ok := m.(*image.NRGBA); ok {
+		return &image.RGBA{Pix: nrgba.Pix, Stride: nrgba.Stride, Rect: nrgba.Rect}
+	}
+
+	bounds := m.Bounds()
+	rgba := image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(m.At(x, y)).(color.NRGBA)
+			rgba.SetRGBA(x, y, color.RGBA{c.R, c.G, c.B, c.A})
+		}
+	}
+	return rgba
+}
@@ -1,44 +1,297 @@
 package animation
 
-import "path/filepath"
+import (
+	"fmt"
+	"image/color"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
 
+// Location describes a single exported skeleton and the files it needs.
+//
+// All paths are relative to FS.
 type Location struct {
-	Name   string
-	Dir    string
+	Name    string
+	Variant string
+
+	FS  fs.FS
+	Dir string
+	// JSON and Binary are empty when there's no such export
 	JSON   string
+	Binary string
 	Atlas  string
-	Images string
-}
-
-var list = []Location{
-	{"Alien", "alien", "alien-ess.json", "alien.atlas", ""},
-	{"Alien Pro", "alien", "alien-pro.json", "alien.atlas", ""},
-	{"Goblins", "goblins", "goblins-ess.json", "goblins.atlas", ""},
-	{"Goblins Pro", "goblins", "goblins-pro.json", "goblins.atlas", ""},
-	{"Powerup", "powerup", "powerup-ess.json", "powerup.atlas", ""},
-	{"Powerup Pro", "powerup", "powerup-pro.json", "powerup.atlas", ""},
-	{"Raptor", "raptor", "raptor-pro.json", "raptor.atlas", ""},
-	{"Speedy", "speedy", "speedy-ess.json", "speedy.atlas", ""},
-	{"Spineboy", "spineboy", "spineboy-ess.json", "spineboy.atlas", ""},
-	{"Spineboy Pro", "spineboy", "spineboy-pro.json", "spineboy.atlas", ""},
-	{"Spinosaurus", "spinosaurus", "spinosaurus-ess.json", "spinosaurus-ess.atlas", ""},
-	{"Stretchyman", "stretchyman", "stretchyman-pro.json", "stretchyman.atlas", ""},
-	{"Tank", "tank", "tank-pro.json", "tank.atlas", ""},
-	{"Vine", "vine", "vine-pro.json", "vine.atlas", ""},
-}
-
-// TODO: read this from folder structure instead
-func LoadList(root string) []Location {
-	xs := make([]Location, 0)
-	for _, loc := range list {
-		dir := filepath.Join(root, loc.Dir)
-		xs = append(xs, Location{
-			Name:   loc.Name,
-			Dir:    dir,
-			JSON:   filepath.Join(dir, "export", loc.JSON),
-			Atlas:  filepath.Join(dir, "export", loc.Atlas),
-			Images: filepath.Join(dir, "images"),
-		})
-	}
-	return xs
+	// PMAAtlas is the premultiplied alpha atlas, empty when not exported
+	PMAAtlas string
+	Images   string
+
+	// Format selects the export used by LoadSkeleton
+	Format Format
+	// PMA selects PMAAtlas for LoadAtlas and LoadPage
+	PMA bool
+
+	// DefaultSkin and DefaultAnimation are empty when not specified
+	DefaultSkin      string
+	DefaultAnimation string
+	// Scale is the preferred display scale
+	Scale float64
+	// Mix is the default crossfade duration between animations,
+	// Mixes overrides it per pair, keyed by from and to animation names
+	Mix   float64
+	Mixes map[string]map[string]float64
+	// Playlists are sequences of animations defined in the manifest
+	Playlists []Playlist
+	// Background is nil when not specified
+	Background color.Color
+	Tags       []string
+	License    string
+
+	// converted caches the binary export converted to JSON
+	converted *conversions
+}
+
+// SkipError describes a directory or export that LoadList could not turn into a Location.
+type SkipError struct {
+	Path   string
+	Reason string
+}
+
+func (err *SkipError) Error() string { return err.Path + ": " + err.Reason }
+
+// ListError collects every SkipError encountered by LoadList.
+type ListError []*SkipError
+
+func (errs ListError) Error() string {
+	lines := make([]string, 0, len(errs))
+	for _, err := range errs {
+		lines = append(lines, err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// LoadList scans the root of fsys for character directories.
+//
+// Every directory is expected to contain an "export" directory with
+// "<name>-<variant>.json" and "<name>-<variant>.skel" (or "<name>.json")
+// skeletons and matching ".atlas" files.
+// Locations that could be resolved are returned even when some directories
+// were skipped, in that case the error is a ListError.
+func LoadList(fsys fs.FS) ([]Location, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var xs []Location
+	var errs ListError
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		locs, skipped := loadDir(fsys, entry.Name())
+		xs = append(xs, locs...)
+		errs = append(errs, skipped...)
+	}
+
+	if len(errs) > 0 {
+		return xs, errs
+	}
+	return xs, nil
+}
+
+// export is a single skeleton found in an export directory.
+type export struct {
+	stem    string
+	variant string
+	json    bool
+	binary  bool
+}
+
+// primary returns the file name of the preferred export.
+func (ex *export) primary() string {
+	if ex.json {
+		return ex.stem + ".json"
+	}
+	return ex.stem + ".skel"
+}
+
+func loadDir(fsys fs.FS, dir string) ([]Location, ListError) {
+	base := path.Base(dir)
+	exportdir := path.Join(dir, "export")
+
+	entries, err := fs.ReadDir(fsys, exportdir)
+	if err != nil {
+		return nil, ListError{{dir, "no export directory"}}
+	}
+
+	exports := map[string]*export{}
+	atlases := []string{}
+	pmaAtlases := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		name := entry.Name()
+		ext := path.Ext(name)
+		stem := strings.TrimSuffix(name, ext)
+		switch ext {
+		case ".atlas":
+			if strings.HasSuffix(stem, "-pma") {
+				pmaAtlases = append(pmaAtlases, strings.TrimSuffix(stem, "-pma")+ext)
+			} else {
+				atlases = append(atlases, name)
+			}
+		case ".json", ".skel":
+			ex, ok := exports[stem]
+			if !ok {
+				ex = &export{stem: stem, variant: variantOf(base, stem)}
+				exports[stem] = ex
+			}
+			if ext == ".json" {
+				ex.json = true
+			} else {
+				ex.binary = true
+			}
+		}
+	}
+
+	if len(exports) == 0 {
+		return nil, ListError{{exportdir, "no skeleton exports"}}
+	}
+
+	manifest, license, err := ReadManifest(fsys, dir)
+	if err != nil {
+		return nil, ListError{{dir, err.Error()}}
+	}
+
+	stems := make([]string, 0, len(exports))
+	for stem := range exports {
+		stems = append(stems, stem)
+	}
+	sort.Strings(stems)
+
+	var xs []Location
+	var errs ListError
+	names := map[string]string{}
+	for _, stem := range stems {
+		ex := exports[stem]
+
+		atlas, err := matchAtlas(base, ex, atlases)
+		if err != nil {
+			errs = append(errs, &SkipError{path.Join(exportdir, ex.primary()), err.Error()})
+			continue
+		}
+
+		name := title(base)
+		if len(exports) > 1 && ex.variant != "" && ex.variant != "ess" {
+			name += " " + title(ex.variant)
+		}
+		if other, exists := names[name]; exists {
+			errs = append(errs, &SkipError{
+				path.Join(exportdir, ex.primary()),
+				fmt.Sprintf("ambiguous name %q, also used by %v", name, other),
+			})
+			continue
+		}
+		names[name] = stem
+
+		loc := Location{
+			Name:    name,
+			Variant: ex.variant,
+			FS:      fsys,
+			Dir:     dir,
+			Atlas:   path.Join(exportdir, atlas),
+			Images:  path.Join(dir, "images"),
+			License: license,
+
+			converted: &conversions{},
+		}
+		if ex.json {
+			loc.JSON = path.Join(exportdir, stem+".json")
+		}
+		if ex.binary {
+			loc.Binary = path.Join(exportdir, stem+".skel")
+		}
+		if !ex.json {
+			loc.Format = FormatBinary
+		}
+		if pma, err := matchAtlas(base, ex, pmaAtlases); err == nil {
+			loc.PMAAtlas = path.Join(exportdir, strings.TrimSuffix(pma, ".atlas")+"-pma.atlas")
+		}
+
+		settings := manifest.Variant(ex.variant)
+		if err := settings.apply(&loc); err != nil {
+			errs = append(errs, &SkipError{dir, err.Error()})
+			continue
+		}
+
+		xs = append(xs, loc)
+	}
+
+	return xs, errs
+}
+
+// variantOf returns the variant of an export, e.g. "pro" for "alien-pro".
+func variantOf(base, stem string) string {
+	if stem == base {
+		return ""
+	}
+	if strings.HasPrefix(stem, base+"-") {
+		return strings.TrimPrefix(stem, base+"-")
+	}
+	return stem
+}
+
+// matchAtlas finds the atlas for an export, preferring
+// "<stem>.atlas", then "<base>-<variant>.atlas" and finally "<base>.atlas".
+func matchAtlas(base string, ex *export, atlases []string) (string, error) {
+	preferred := []string{ex.stem + ".atlas"}
+	if ex.variant != "" {
+		preferred = append(preferred, base+"-"+ex.variant+".atlas")
+	}
+	preferred = append(preferred, base+".atlas")
+
+	for _, name := range preferred {
+		for _, atlas := range atlases {
+			if atlas == name {
+				return atlas, nil
+			}
+		}
+	}
+
+	switch len(atlases) {
+	case 0:
+		return "", fmt.Errorf("no atlas")
+	case 1:
+		return atlases[0], nil
+	default:
+		return "", fmt.Errorf("ambiguous atlas %v", strings.Join(atlases, ", "))
+	}
+}
+
+// title converts "spine-boy" into "Spine Boy".
+func title(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return r == '-' || r == '_' || r == ' '
+	})
+	for i, word := range words {
+		r, size := utf8.DecodeRuneInString(word)
+		words[i] = string(unicode.ToUpper(r)) + word[size:]
+	}
+	return strings.Join(words, " ")
+}
+
+// Find returns the location with the specified name, ignoring case.
+func Find(locs []Location, name string) (Location, bool) {
+	for _, loc := range locs {
+		if strings.EqualFold(loc.Name, name) {
+			return loc, true
+		}
+	}
+	return Location{}, false
 }
@@ -0,0 +1,119 @@
+package animation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadList(t *testing.T) {
+	fsys := fstest.MapFS{
+		"alien/export/alien-ess.json":  {},
+		"alien/export/alien-ess.skel":  {},
+		"alien/export/alien-pro.json":  {},
+		"alien/export/alien-pro.skel":  {},
+		"alien/export/alien.atlas":     {},
+		"alien/export/alien-pma.atlas": {},
+		"alien/images/head.png":        {},
+
+		"spineboy/export/spineboy.skel":  {},
+		"spineboy/export/spineboy.atlas": {},
+		"spineboy/manifest.json":         {Data: []byte(`{"animation": "walk", "scale": 0.5}`)},
+
+		".git/export/x.json": {},
+		"readme.txt":         {},
+	}
+
+	locs, err := LoadList(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type expect struct {
+		name, variant string
+		json, binary  string
+		atlas, pma    string
+		format        Format
+	}
+	exp := []expect{
+		{"Alien", "ess", "alien/export/alien-ess.json", "alien/export/alien-ess.skel", "alien/export/alien.atlas", "alien/export/alien-pma.atlas", FormatJSON},
+		{"Alien Pro", "pro", "alien/export/alien-pro.json", "alien/export/alien-pro.skel", "alien/export/alien.atlas", "alien/export/alien-pma.atlas", FormatJSON},
+		{"Spineboy", "", "", "spineboy/export/spineboy.skel", "spineboy/export/spineboy.atlas", "", FormatBinary},
+	}
+	if len(locs) != len(exp) {
+		t.Fatalf("got %d locations, expected %d: %+v", len(locs), len(exp), locs)
+	}
+	for i, loc := range locs {
+		got := expect{loc.Name, loc.Variant, loc.JSON, loc.Binary, loc.Atlas, loc.PMAAtlas, loc.Format}
+		if got != exp[i] {
+			t.Errorf("%d: got %+v, expected %+v", i, got, exp[i])
+		}
+	}
+
+	if spineboy := locs[2]; spineboy.DefaultAnimation != "walk" || spineboy.Scale != 0.5 {
+		t.Errorf("manifest not applied: %q %v", spineboy.DefaultAnimation, spineboy.Scale)
+	}
+	if alien := locs[0]; alien.Scale != 1 || alien.Images != "alien/images" {
+		t.Errorf("defaults not applied: %v %q", alien.Scale, alien.Images)
+	}
+}
+
+func TestLoadListSkipped(t *testing.T) {
+	fsys := fstest.MapFS{
+		"empty/images/a.png": {},
+
+		"noskeleton/export/noskeleton.atlas": {},
+
+		"noatlas/export/noatlas.json": {},
+
+		"twoatlases/export/twoatlases.json": {},
+		"twoatlases/export/first.atlas":     {},
+		"twoatlases/export/second.atlas":    {},
+
+		"raptor/export/raptor.json":     {},
+		"raptor/export/raptor-ess.json": {},
+		"raptor/export/raptor.atlas":    {},
+	}
+
+	locs, err := LoadList(fsys)
+
+	var errs ListError
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected ListError, got %v", err)
+	}
+
+	if len(locs) != 1 || locs[0].Name != "Raptor" || locs[0].Variant != "" {
+		t.Errorf("expected only Raptor to be loaded, got %+v", locs)
+	}
+
+	exp := []struct{ path, reason string }{
+		{"empty", "no export directory"},
+		{"noatlas/export/noatlas.json", "no atlas"},
+		{"noskeleton/export", "no skeleton exports"},
+		{"raptor/export/raptor-ess.json", "ambiguous name"},
+		{"twoatlases/export/twoatlases.json", "ambiguous atlas"},
+	}
+	if len(errs) != len(exp) {
+		t.Fatalf("got %d errors, expected %d:\n%v", len(errs), len(exp), errs)
+	}
+	for i, skip := range errs {
+		if skip.Path != exp[i].path || !strings.HasPrefix(skip.Reason, exp[i].reason) {
+			t.Errorf("%d: got %v, expected %v: %v", i, skip, exp[i].path, exp[i].reason)
+		}
+	}
+}
+
+func TestTitle(t *testing.T) {
+	tests := []struct{ in, exp string }{
+		{"spineboy", "Spineboy"},
+		{"spine-boy", "Spine Boy"},
+		{"stretchy_man", "Stretchy Man"},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if got := title(test.in); got != test.exp {
+			t.Errorf("title(%q) = %q, expected %q", test.in, got, test.exp)
+		}
+	}
+}
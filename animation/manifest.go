@@ -0,0 +1,183 @@
+package animation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Manifest contains per-character settings, it is read from
+// "manifest.json" next to the "export" directory.
+//
+// Variants can override any of the settings for a specific export, e.g.
+//
+//	{
+//		"animation": "walk",
+//		"scale": 0.5,
+//		"background": "#d3d3d3",
+//		"format": "binary",
+//		"mix": 0.2,
+//		"mixes": { "jump": { "run": 0.4 } },
+//		"playlists": [
+//			{ "name": "jump", "entries": [
+//				{ "animation": "jump" },
+//				{ "animation": "run", "loops": 3 },
+//				{ "animation": "idle", "loops": 0 }
+//			]}
+//		],
+//		"tags": ["biped"],
+//		"variants": {
+//			"pro": { "animation": "run" }
+//		}
+//	}
+type Manifest struct {
+	Skin       string   `json:"skin,omitempty"`
+	Animation  string   `json:"animation,omitempty"`
+	Scale      float64  `json:"scale,omitempty"`
+	Background string   `json:"background,omitempty"`
+	Format     string   `json:"format,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+
+	// Mix is the default crossfade duration between animations in seconds,
+	// Mixes overrides it for specific pairs, keyed by from and to
+	Mix   float64                       `json:"mix,omitempty"`
+	Mixes map[string]map[string]float64 `json:"mixes,omitempty"`
+
+	Playlists []Playlist `json:"playlists,omitempty"`
+
+	Variants map[string]*Manifest `json:"variants,omitempty"`
+}
+
+// ReadManifest reads the manifest and the license of a character directory in fsys.
+//
+// Missing files are not an error, in that case the zero values are returned.
+func ReadManifest(fsys fs.FS, dir string) (manifest *Manifest, license string, err error) {
+	manifest = &Manifest{}
+
+	data, err := fs.ReadFile(fsys, path.Join(dir, "manifest.json"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, "", err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, manifest); err != nil {
+			return nil, "", fmt.Errorf("manifest.json: %v", err)
+		}
+	}
+
+	licensedata, err := fs.ReadFile(fsys, path.Join(dir, "license.txt"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, "", err
+	}
+
+	return manifest, string(licensedata), nil
+}
+
+// Variant returns the manifest with the overrides for variant applied.
+func (manifest *Manifest) Variant(variant string) Manifest {
+	result := *manifest
+	result.Variants = nil
+
+	override, ok := manifest.Variants[variant]
+	if !ok {
+		return result
+	}
+
+	if override.Skin != "" {
+		result.Skin = override.Skin
+	}
+	if override.Animation != "" {
+		result.Animation = override.Animation
+	}
+	if override.Scale != 0 {
+		result.Scale = override.Scale
+	}
+	if override.Background != "" {
+		result.Background = override.Background
+	}
+	if override.Format != "" {
+		result.Format = override.Format
+	}
+	if override.Mix != 0 {
+		result.Mix = override.Mix
+	}
+	if len(override.Playlists) > 0 {
+		result.Playlists = override.Playlists
+	}
+	if len(override.Mixes) > 0 {
+		result.Mixes = map[string]map[string]float64{}
+		for _, mixes := range []map[string]map[string]float64{manifest.Mixes, override.Mixes} {
+			for from, targets := range mixes {
+				if result.Mixes[from] == nil {
+					result.Mixes[from] = map[string]float64{}
+				}
+				for to, duration := range targets {
+					result.Mixes[from][to] = duration
+				}
+			}
+		}
+	}
+	result.Tags = append(append([]string{}, manifest.Tags...), override.Tags...)
+
+	return result
+}
+
+// apply copies the settings from the manifest to loc.
+func (manifest *Manifest) apply(loc *Location) error {
+	loc.DefaultSkin = manifest.Skin
+	loc.DefaultAnimation = manifest.Animation
+	loc.Tags = manifest.Tags
+	loc.Mix = manifest.Mix
+	loc.Mixes = manifest.Mixes
+	loc.Playlists = manifest.Playlists
+
+	loc.Scale = manifest.Scale
+	if loc.Scale == 0 {
+		loc.Scale = 1
+	}
+
+	if manifest.Background != "" {
+		background, err := ParseColor(manifest.Background)
+		if err != nil {
+			return fmt.Errorf("manifest.json: background: %v", err)
+		}
+		loc.Background = background
+	}
+
+	if manifest.Format != "" {
+		format, err := ParseFormat(manifest.Format)
+		if err != nil {
+			return fmt.Errorf("manifest.json: format: %v", err)
+		}
+		loc.Prefer(format)
+	}
+
+	return nil
+}
+
+// ParseColor parses colors in "#rrggbb" or "#rrggbbaa" format.
+func ParseColor(s string) (color.NRGBA, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) != 6 && len(hex) != 8 {
+		return color.NRGBA{}, fmt.Errorf("invalid color %q", s)
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("invalid color %q", s)
+	}
+
+	return color.NRGBA{
+		R: uint8(v >> 24),
+		G: uint8(v >> 16),
+		B: uint8(v >> 8),
+		A: uint8(v),
+	}, nil
+}
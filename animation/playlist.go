@@ -0,0 +1,28 @@
+package animation
+
+// Playlist is a named sequence of animations.
+type Playlist struct {
+	Name    string          `json:"name"`
+	Entries []PlaylistEntry `json:"entries"`
+}
+
+// PlaylistEntry is an animation in a playlist.
+type PlaylistEntry struct {
+	Animation string `json:"animation"`
+	// Loops is the number of times the animation plays, 0 plays it
+	// forever and the following entries never start, defaults to 1
+	Loops *int `json:"loops,omitempty"`
+	// Delay is the pause in seconds after the previous entry completes
+	Delay float64 `json:"delay,omitempty"`
+	// Mix is the crossfade duration from the previous entry in seconds,
+	// defaults to the mixes of the character
+	Mix *float64 `json:"mix,omitempty"`
+}
+
+// LoopCount returns the number of times the entry plays, 0 means forever.
+func (entry *PlaylistEntry) LoopCount() int {
+	if entry.Loops == nil {
+		return 1
+	}
+	return *entry.Loops
+}
@@ -0,0 +1,154 @@
+package animation
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"sync"
+
+	"github.com/adinfinit/spine"
+	"github.com/adinfinit/spine-examples/metadata"
+	"github.com/adinfinit/spine-examples/skel"
+)
+
+// Format is the encoding of a skeleton export.
+type Format int
+
+const (
+	FormatJSON Format = iota
+	FormatBinary
+)
+
+func (format Format) String() string {
+	switch format {
+	case FormatJSON:
+		return "json"
+	case FormatBinary:
+		return "binary"
+	default:
+		return fmt.Sprintf("Format(%d)", int(format))
+	}
+}
+
+// ParseFormat parses "json" or "binary".
+func ParseFormat(s string) (Format, error) {
+	switch s {
+	case "json":
+		return FormatJSON, nil
+	case "binary":
+		return FormatBinary, nil
+	default:
+		return 0, fmt.Errorf("invalid format %q", s)
+	}
+}
+
+// Has returns whether the location has an export in the specified format.
+func (loc *Location) Has(format Format) bool {
+	switch format {
+	case FormatJSON:
+		return loc.JSON != ""
+	case FormatBinary:
+		return loc.Binary != ""
+	default:
+		return false
+	}
+}
+
+// Prefer switches to format when the location has such export.
+func (loc *Location) Prefer(format Format) {
+	if loc.Has(format) {
+		loc.Format = format
+	}
+}
+
+// Skeleton returns the path of the export in loc.Format.
+func (loc *Location) Skeleton() string {
+	if loc.Format == FormatBinary {
+		return loc.Binary
+	}
+	return loc.JSON
+}
+
+// SkeletonJSON returns the skeleton in the JSON format,
+// binary exports are converted once and shared by the copies of loc.
+func (loc *Location) SkeletonJSON() ([]byte, error) {
+	if !loc.Has(loc.Format) {
+		return nil, fmt.Errorf("%v: no %v export", loc.Name, loc.Format)
+	}
+
+	if loc.Format != FormatBinary {
+		return fs.ReadFile(loc.FS, loc.JSON)
+	}
+
+	if loc.converted == nil {
+		loc.converted = &conversions{}
+	}
+	return loc.converted.get(loc.FS, loc.Binary)
+}
+
+// conversions caches binary exports converted to JSON, keyed by path.
+type conversions struct {
+	mu   sync.Mutex
+	json map[string][]byte
+}
+
+func (cache *conversions) get(fsys fs.FS, name string) ([]byte, error) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if data, ok := cache.json[name]; ok {
+		return data, nil
+	}
+
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	data, err = skel.ToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", name, err)
+	}
+
+	if cache.json == nil {
+		cache.json = map[string][]byte{}
+	}
+	cache.json[name] = data
+	return data, nil
+}
+
+// LoadSkeleton reads the skeleton data in loc.Format.
+func (loc *Location) LoadSkeleton() (*spine.SkeletonData, error) {
+	content, err := loc.SkeletonJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := spine.ReadJSON(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", loc.Skeleton(), err)
+	}
+
+	data.Name = loc.Name
+	return data, nil
+}
+
+// LoadMetadata reads the information that spine.SkeletonData doesn't contain.
+func (loc *Location) LoadMetadata() (*metadata.Skeleton, error) {
+	content, err := loc.SkeletonJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := metadata.Read(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", loc.Skeleton(), err)
+	}
+	return meta, nil
+}
+
+// PreferFormat switches every location that has a format export to it.
+func PreferFormat(locs []Location, format Format) {
+	for i := range locs {
+		locs[i].Prefer(format)
+	}
+}
@@ -0,0 +1,290 @@
+package animation
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+// exported contains the parts of a JSON export that are compared.
+type exported struct {
+	Bones []struct {
+		Name     string
+		Parent   string
+		Length   float64
+		Rotation float64
+		X, Y     float64
+	}
+	Slots []struct {
+		Name       string
+		Bone       string
+		Attachment string
+		Blend      string
+	}
+	Skins      map[string]map[string]map[string]json.RawMessage
+	Events     map[string]json.RawMessage
+	Animations map[string]map[string]json.RawMessage
+}
+
+func TestBinaryMatchesJSON(t *testing.T) {
+	locs, err := LoadList(os.DirFS("."))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compared := 0
+	for _, loc := range locs {
+		if !loc.Has(FormatJSON) || !loc.Has(FormatBinary) {
+			continue
+		}
+		compared++
+
+		loc.Prefer(FormatJSON)
+		text := readExport(t, &loc)
+		loc.Prefer(FormatBinary)
+		binary := readExport(t, &loc)
+
+		t.Run(loc.Binary, func(t *testing.T) {
+			if len(text.Bones) != len(binary.Bones) {
+				t.Fatalf("bones: got %d, expected %d", len(binary.Bones), len(text.Bones))
+			}
+			for i, exp := range text.Bones {
+				got := binary.Bones[i]
+				if got.Name != exp.Name || got.Parent != exp.Parent {
+					t.Errorf("bone %d: got %q parent %q, expected %q parent %q", i, got.Name, got.Parent, exp.Name, exp.Parent)
+				}
+				for _, v := range [][2]float64{
+					{got.Length, exp.Length}, {got.Rotation, exp.Rotation}, {got.X, exp.X}, {got.Y, exp.Y},
+				} {
+					if math.Abs(v[0]-v[1]) > 0.01 {
+						t.Errorf("bone %q: got %+v, expected %+v", exp.Name, got, exp)
+						break
+					}
+				}
+			}
+
+			if !reflect.DeepEqual(text.Slots, binary.Slots) {
+				t.Errorf("slots differ:\ngot      %+v\nexpected %+v", binary.Slots, text.Slots)
+			}
+
+			if got, exp := skinAttachments(binary), skinAttachments(text); !reflect.DeepEqual(got, exp) {
+				t.Errorf("skins differ:\ngot      %v\nexpected %v", got, exp)
+			}
+
+			if got, exp := sortedKeys(binary.Events), sortedKeys(text.Events); !reflect.DeepEqual(got, exp) {
+				t.Errorf("events: got %v, expected %v", got, exp)
+			}
+
+			if got, exp := sortedKeys(binary.Animations), sortedKeys(text.Animations); !reflect.DeepEqual(got, exp) {
+				t.Fatalf("animations: got %v, expected %v", got, exp)
+			}
+			for name, exp := range text.Animations {
+				got := binary.Animations[name]
+				if !reflect.DeepEqual(sortedKeys(got), sortedKeys(exp)) {
+					t.Errorf("animation %q timelines: got %v, expected %v", name, sortedKeys(got), sortedKeys(exp))
+					continue
+				}
+				for timeline, keys := range exp {
+					for _, diff := range compareJSON(timeline, decodeJSON(t, got[timeline]), decodeJSON(t, keys)) {
+						t.Errorf("animation %q: %v", name, diff)
+					}
+				}
+			}
+		})
+	}
+
+	if compared == 0 {
+		t.Fatal("no locations with both exports")
+	}
+}
+
+func TestSkeletonJSONCached(t *testing.T) {
+	locs, err := LoadList(os.DirFS("."))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, loc := range locs {
+		if !loc.Has(FormatBinary) {
+			continue
+		}
+		loc.Prefer(FormatBinary)
+
+		first, err := loc.SkeletonJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		copied := loc
+		second, err := copied.SkeletonJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if &first[0] != &second[0] {
+			t.Errorf("%v: binary export converted twice", loc.Name)
+		}
+		return
+	}
+	t.Fatal("no binary exports")
+}
+
+func readExport(t *testing.T, loc *Location) *exported {
+	t.Helper()
+
+	content, err := loc.SkeletonJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := &exported{}
+	if err := json.Unmarshal(content, result); err != nil {
+		t.Fatalf("%v: %v", loc.Skeleton(), err)
+	}
+	return result
+}
+
+// keyTolerance is the largest allowed difference of a keyed value,
+// the JSON export rounds values to two decimals
+const keyTolerance = 0.01
+
+func decodeJSON(t *testing.T, data json.RawMessage) interface{} {
+	t.Helper()
+
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+// compareJSON returns the differences between two decoded JSON keys,
+// numbers are compared with keyTolerance and colors per channel.
+//
+// Curves are skipped, the example exports disagree on some of them
+// (spine-c reads the same curves from the binaries as skel).
+// Deform vertices are compared after expanding the offset, since
+// the exports trim zeros differently.
+func compareJSON(path string, got, exp interface{}) []string {
+	switch exp := exp.(type) {
+	case map[string]interface{}:
+		got, ok := got.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%v: got %v, expected an object", path, got)}
+		}
+		got, exp = normalizeKey(got), normalizeKey(exp)
+		if !reflect.DeepEqual(sortedKeys(got), sortedKeys(exp)) {
+			return []string{fmt.Sprintf("%v: got keys %v, expected %v", path, sortedKeys(got), sortedKeys(exp))}
+		}
+		var diffs []string
+		for _, key := range sortedKeys(exp) {
+			diffs = append(diffs, compareJSON(path+"."+key, got[key], exp[key])...)
+		}
+		return diffs
+	case []interface{}:
+		got, ok := got.([]interface{})
+		if !ok || len(got) != len(exp) {
+			return []string{fmt.Sprintf("%v: got %v, expected %v", path, got, exp)}
+		}
+		var diffs []string
+		for i := range exp {
+			diffs = append(diffs, compareJSON(fmt.Sprintf("%v[%d]", path, i), got[i], exp[i])...)
+		}
+		return diffs
+	case float64:
+		got, ok := got.(float64)
+		if !ok || math.Abs(got-exp) > keyTolerance {
+			return []string{fmt.Sprintf("%v: got %v, expected %v", path, got, exp)}
+		}
+		return nil
+	case string:
+		got, ok := got.(string)
+		if !ok || got != exp && !similarColors(got, exp) {
+			return []string{fmt.Sprintf("%v: got %v, expected %v", path, got, exp)}
+		}
+		return nil
+	default:
+		if !reflect.DeepEqual(got, exp) {
+			return []string{fmt.Sprintf("%v: got %v, expected %v", path, got, exp)}
+		}
+		return nil
+	}
+}
+
+// normalizeKey returns a copy of key without the curve,
+// with deform vertices expanded from offset.
+func normalizeKey(key map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for name, value := range key {
+		result[name] = value
+	}
+	delete(result, "curve")
+
+	if _, ok := key["time"]; !ok {
+		return result
+	}
+	vertices, ok := result["vertices"].([]interface{})
+	if !ok {
+		return result
+	}
+	offset, _ := result["offset"].(float64)
+	delete(result, "offset")
+
+	dense := make([]interface{}, int(offset), int(offset)+len(vertices))
+	for i := range dense {
+		dense[i] = 0.0
+	}
+	dense = append(dense, vertices...)
+	// trailing zeros are omitted by the JSON export
+	for len(dense) > 0 && math.Abs(dense[len(dense)-1].(float64)) < keyTolerance {
+		dense = dense[:len(dense)-1]
+	}
+	if len(dense) == 0 {
+		delete(result, "vertices")
+		return result
+	}
+	result["vertices"] = dense
+	return result
+}
+
+// similarColors returns whether a and b are hex colors,
+// whose channels differ by at most one.
+func similarColors(a, b string) bool {
+	if len(a) != len(b) || len(a)%2 != 0 {
+		return false
+	}
+	for i := 0; i < len(a); i += 2 {
+		x, errx := strconv.ParseUint(a[i:i+2], 16, 8)
+		y, erry := strconv.ParseUint(b[i:i+2], 16, 8)
+		if errx != nil || erry != nil || x > y+1 || y > x+1 {
+			return false
+		}
+	}
+	return true
+}
+
+// skinAttachments returns "skin/slot/attachment" for every attachment.
+func skinAttachments(ex *exported) []string {
+	var names []string
+	for skin, slots := range ex.Skins {
+		for slot, attachments := range slots {
+			for attachment := range attachments {
+				names = append(names, skin+"/"+slot+"/"+attachment)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
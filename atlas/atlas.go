@@ -0,0 +1,307 @@
+// Package atlas implements a parser for Spine texture atlases (.atlas).
+package atlas
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Atlas describes how regions are packed into page images.
+type Atlas struct {
+	Pages   []*Page
+	Regions []*Region
+}
+
+// Page is a single texture of the atlas.
+type Page struct {
+	// Name is the file name of the texture relative to the atlas
+	Name string
+
+	Width, Height int
+	Format        string
+
+	MinFilter, MagFilter Filter
+	RepeatX, RepeatY     bool
+
+	Regions []*Region
+}
+
+// Region is a rectangle in a page.
+//
+// When whitespace has been stripped, the packed rectangle is smaller
+// than the original image and Offset specifies its position inside it.
+type Region struct {
+	Page *Page
+	Name string
+
+	// Rotate means that the region is stored rotated
+	// 90 degrees counter-clockwise in the page
+	Rotate bool
+
+	// X, Y is the top-left corner in the page
+	X, Y int
+	// Width, Height is the size of the unrotated region
+	Width, Height int
+
+	// OrigWidth, OrigHeight is the size before stripping whitespace
+	OrigWidth, OrigHeight int
+	// OffsetX, OffsetY is the position in the original image,
+	// measured from the bottom-left corner
+	OffsetX, OffsetY int
+
+	// Index is -1 when the region is not part of a sequence
+	Index int
+
+	// Split and Pad are nil unless the region is a ninepatch
+	Split []int
+	Pad   []int
+}
+
+// Filter is a texture filter.
+type Filter int
+
+const (
+	Nearest Filter = iota
+	Linear
+	MipMap
+	MipMapNearestNearest
+	MipMapLinearNearest
+	MipMapNearestLinear
+	MipMapLinearLinear
+)
+
+var filterNames = []string{
+	"Nearest",
+	"Linear",
+	"MipMap",
+	"MipMapNearestNearest",
+	"MipMapLinearNearest",
+	"MipMapNearestLinear",
+	"MipMapLinearLinear",
+}
+
+func (filter Filter) String() string {
+	if filter < 0 || int(filter) >= len(filterNames) {
+		return "Filter(" + strconv.Itoa(int(filter)) + ")"
+	}
+	return filterNames[filter]
+}
+
+// Smooth returns whether the filter interpolates between texels.
+func (filter Filter) Smooth() bool { return filter != Nearest && filter != MipMapNearestNearest }
+
+func parseFilter(s string) (Filter, error) {
+	for i, name := range filterNames {
+		if name == s {
+			return Filter(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown filter %q", s)
+}
+
+// Find returns the region with the specified name.
+//
+// When there are multiple regions with the same name,
+// the one with the smallest index is returned.
+func (atlas *Atlas) Find(name string) *Region {
+	var found *Region
+	for _, region := range atlas.Regions {
+		if region.Name != name {
+			continue
+		}
+		if found == nil || region.Index < found.Index {
+			found = region
+		}
+	}
+	return found
+}
+
+// Bounds returns the packed rectangle in the page.
+func (region *Region) Bounds() image.Rectangle {
+	w, h := region.Width, region.Height
+	if region.Rotate {
+		w, h = h, w
+	}
+	return image.Rect(region.X, region.Y, region.X+w, region.Y+h)
+}
+
+// Center returns the center of the packed rectangle relative
+// to the center of the original image, y pointing up.
+func (region *Region) Center() (x, y float64) {
+	x = float64(region.OffsetX) + float64(region.Width)*0.5 - float64(region.OrigWidth)*0.5
+	y = float64(region.OffsetY) + float64(region.Height)*0.5 - float64(region.OrigHeight)*0.5
+	return x, y
+}
+
+// PagePoint converts a point in the original image to page pixels,
+// both with the origin at top-left and y pointing down.
+func (region *Region) PagePoint(x, y float64) (px, py float64) {
+	// position inside the packed, unrotated, region
+	top := region.OrigHeight - region.OffsetY - region.Height
+	x -= float64(region.OffsetX)
+	y -= float64(top)
+
+	if region.Rotate {
+		return float64(region.X) + y, float64(region.Y+region.Width) - x
+	}
+	return float64(region.X) + x, float64(region.Y) + y
+}
+
+// PageUV converts a texture coordinate relative to the original image
+// into a texture coordinate of the page, both with v pointing down.
+func (region *Region) PageUV(u, v float64) (pu, pv float64) {
+	px, py := region.PagePoint(u*float64(region.OrigWidth), v*float64(region.OrigHeight))
+	return px / float64(region.Page.Width), py / float64(region.Page.Height)
+}
+
+// Parse parses an atlas in the libgdx text format.
+func Parse(rd io.Reader) (*Atlas, error) {
+	atlas := &Atlas{}
+
+	scanner := bufio.NewScanner(rd)
+	var page *Page
+	var region *Region
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if lineNumber == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+
+		if strings.TrimSpace(line) == "" {
+			page, region = nil, nil
+			continue
+		}
+
+		fail := func(format string, args ...interface{}) error {
+			return fmt.Errorf("atlas:%d: %v", lineNumber, fmt.Sprintf(format, args...))
+		}
+
+		colon := strings.IndexByte(line, ':')
+		if colon < 0 {
+			name := strings.TrimSpace(line)
+			if page == nil {
+				page = &Page{Name: name}
+				atlas.Pages = append(atlas.Pages, page)
+				continue
+			}
+
+			region = &Region{Page: page, Name: name, Index: -1}
+			page.Regions = append(page.Regions, region)
+			atlas.Regions = append(atlas.Regions, region)
+			continue
+		}
+		if page == nil {
+			return nil, fail("property outside of a page")
+		}
+
+		key := strings.TrimSpace(line[:colon])
+		values := strings.Split(line[colon+1:], ",")
+		for i := range values {
+			values[i] = strings.TrimSpace(values[i])
+		}
+
+		var err error
+		if region == nil {
+			err = page.set(key, values)
+		} else {
+			err = region.set(key, values)
+		}
+		if err != nil {
+			return nil, fail("%v: %v", key, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, region := range atlas.Regions {
+		if region.OrigWidth == 0 && region.OrigHeight == 0 {
+			region.OrigWidth, region.OrigHeight = region.Width, region.Height
+		}
+	}
+
+	return atlas, nil
+}
+
+func (page *Page) set(key string, values []string) error {
+	var err error
+	switch key {
+	case "size":
+		err = parseInts(values, &page.Width, &page.Height)
+	case "format":
+		page.Format = values[0]
+	case "filter":
+		if len(values) != 2 {
+			return fmt.Errorf("expected 2 values")
+		}
+		if page.MinFilter, err = parseFilter(values[0]); err != nil {
+			return err
+		}
+		page.MagFilter, err = parseFilter(values[1])
+	case "repeat":
+		switch values[0] {
+		case "x":
+			page.RepeatX = true
+		case "y":
+			page.RepeatY = true
+		case "xy":
+			page.RepeatX, page.RepeatY = true, true
+		case "none":
+		default:
+			return fmt.Errorf("unknown repeat %q", values[0])
+		}
+	}
+	return err
+}
+
+func (region *Region) set(key string, values []string) error {
+	var err error
+	switch key {
+	case "rotate":
+		switch values[0] {
+		case "true", "90":
+			region.Rotate = true
+		case "false", "0":
+			region.Rotate = false
+		default:
+			return fmt.Errorf("unsupported rotation %q", values[0])
+		}
+	case "xy":
+		err = parseInts(values, &region.X, &region.Y)
+	case "size":
+		err = parseInts(values, &region.Width, &region.Height)
+	case "orig":
+		err = parseInts(values, &region.OrigWidth, &region.OrigHeight)
+	case "offset":
+		err = parseInts(values, &region.OffsetX, &region.OffsetY)
+	case "index":
+		err = parseInts(values, &region.Index)
+	case "split":
+		region.Split = make([]int, 4)
+		err = parseInts(values, &region.Split[0], &region.Split[1], &region.Split[2], &region.Split[3])
+	case "pad":
+		region.Pad = make([]int, 4)
+		err = parseInts(values, &region.Pad[0], &region.Pad[1], &region.Pad[2], &region.Pad[3])
+	}
+	return err
+}
+
+func parseInts(values []string, targets ...*int) error {
+	if len(values) != len(targets) {
+		return fmt.Errorf("expected %d values, got %d", len(targets), len(values))
+	}
+	for i, value := range values {
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		*targets[i] = v
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package atlas
+
+import (
+	"image"
+	"strings"
+	"testing"
+)
+
+const example = "\ufeff" + `
+alien.png
+size: 256,128
+format: RGBA8888
+filter: Linear,MipMapLinearLinear
+repeat: none
+head
+  rotate: true
+  xy: 10, 20
+  size: 30, 40
+  orig: 50, 60
+  offset: 5, 6
+  index: -1
+eye
+  rotate: false
+  xy: 100, 0
+  size: 8, 8
+  index: 1
+eye
+  rotate: 90
+  xy: 110, 0
+  size: 8, 8
+  index: 0
+
+alien2.png
+size: 64,64
+repeat: xy
+patch
+  xy: 0, 0
+  size: 16, 16
+  split: 1, 2, 3, 4
+  pad: 0, 0, 0, 0
+`
+
+func TestParse(t *testing.T) {
+	atlas, err := Parse(strings.NewReader(example))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(atlas.Pages) != 2 || len(atlas.Regions) != 4 {
+		t.Fatalf("got %d pages and %d regions", len(atlas.Pages), len(atlas.Regions))
+	}
+
+	page := atlas.Pages[0]
+	if page.Name != "alien.png" || page.Width != 256 || page.Height != 128 || page.Format != "RGBA8888" {
+		t.Errorf("page: got %+v", page)
+	}
+	if page.MinFilter != Linear || page.MagFilter != MipMapLinearLinear || page.RepeatX || page.RepeatY {
+		t.Errorf("page filters: got %v %v %v %v", page.MinFilter, page.MagFilter, page.RepeatX, page.RepeatY)
+	}
+	if second := atlas.Pages[1]; !second.RepeatX || !second.RepeatY || len(second.Regions) != 1 {
+		t.Errorf("second page: got %+v", second)
+	}
+
+	head := atlas.Find("head")
+	if head == nil || head.Page != page {
+		t.Fatalf("head: got %+v", head)
+	}
+	if !head.Rotate || head.X != 10 || head.Y != 20 || head.Width != 30 || head.Height != 40 {
+		t.Errorf("head rectangle: got %+v", head)
+	}
+	if head.OrigWidth != 50 || head.OrigHeight != 60 || head.OffsetX != 5 || head.OffsetY != 6 {
+		t.Errorf("head orig and offset: got %+v", head)
+	}
+
+	eye := atlas.Find("eye")
+	if eye == nil || eye.Index != 0 || !eye.Rotate || eye.X != 110 {
+		t.Errorf("eye: expected the region with the lowest index, got %+v", eye)
+	}
+	if eye.OrigWidth != 8 || eye.OrigHeight != 8 {
+		t.Errorf("eye: orig should default to size, got %v %v", eye.OrigWidth, eye.OrigHeight)
+	}
+
+	patch := atlas.Find("patch")
+	if patch == nil || len(patch.Split) != 4 || patch.Split[3] != 4 || len(patch.Pad) != 4 {
+		t.Errorf("patch: got %+v", patch)
+	}
+}
+
+func TestRegionGeometry(t *testing.T) {
+	atlas, err := Parse(strings.NewReader(example))
+	if err != nil {
+		t.Fatal(err)
+	}
+	head := atlas.Find("head")
+	plain := atlas.Regions[1]
+
+	if got, exp := head.Bounds(), image.Rect(10, 20, 50, 50); got != exp {
+		t.Errorf("head bounds: got %v, expected %v", got, exp)
+	}
+	if got, exp := plain.Bounds(), image.Rect(100, 0, 108, 8); got != exp {
+		t.Errorf("eye bounds: got %v, expected %v", got, exp)
+	}
+
+	if x, y := head.Center(); x != -5 || y != -4 {
+		t.Errorf("head center: got %v %v", x, y)
+	}
+
+	tests := []struct {
+		region       *Region
+		x, y, px, py float64
+	}{
+		// top-left and top-right of the packed rectangle inside the original image
+		{head, 5, 14, 10, 50},
+		{head, 35, 14, 10, 20},
+		// bottom-left
+		{head, 5, 54, 50, 50},
+		{plain, 0, 0, 100, 0},
+		{plain, 8, 8, 108, 8},
+	}
+	for _, test := range tests {
+		px, py := test.region.PagePoint(test.x, test.y)
+		if px != test.px || py != test.py {
+			t.Errorf("%v.PagePoint(%v, %v): got %v %v, expected %v %v",
+				test.region.Name, test.x, test.y, px, py, test.px, test.py)
+		}
+	}
+
+	if u, v := plain.PageUV(1, 1); u != 108.0/256 || v != 8.0/128 {
+		t.Errorf("eye uv: got %v %v", u, v)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct{ name, input, err string }{
+		{"property outside page", "size: 1,1\n", "atlas:1: property outside of a page"},
+		{"filter", "a.png\nfilter: Linear\n", "atlas:2: filter: expected 2 values"},
+		{"rotation", "a.png\nr\n rotate: 45\n", "atlas:3: rotate: unsupported rotation \"45\""},
+		{"size", "a.png\nsize: 1\n", "atlas:2: size: expected 2 values, got 1"},
+	}
+	for _, test := range tests {
+		_, err := Parse(strings.NewReader(test.input))
+		if err == nil || err.Error() != test.err {
+			t.Errorf("%v: got %v, expected %v", test.name, err, test.err)
+		}
+	}
+}
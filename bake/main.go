@@ -0,0 +1,280 @@
+// bake renders the animations of a character into sprite sheets
+// and writes a JSON descriptor with the frame layout and events.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/adinfinit/spine-examples/animation"
+	"github.com/adinfinit/spine-examples/character"
+	"github.com/adinfinit/spine-examples/headless"
+	"github.com/adinfinit/spine-examples/metadata"
+)
+
+var (
+	assetsDir    = flag.String("assets", "../animation", "animation directory")
+	preferBinary = flag.Bool("binary", false, "load binary .skel exports when available")
+	pma          = flag.Bool("pma", false, "use premultiplied alpha atlases when available")
+	location     = flag.String("location", "", "character name, all characters when empty")
+	skins        = flag.String("skin", "", "comma separated skins or \"all\", defaults to the character default")
+	fps          = flag.Float64("fps", 30, "frames per second")
+	scale        = flag.Float64("scale", 0, "target scale, defaults to the character scale")
+	maxSize      = flag.Int("max-size", 2048, "maximum sheet width and height")
+	spacing      = flag.Int("spacing", 1, "pixels between frames")
+	outputDir    = flag.String("o", "baked", "output directory")
+)
+
+// Descriptor describes the sheets of a character skin.
+type Descriptor struct {
+	Character  string      `json:"character"`
+	Skin       string      `json:"skin"`
+	Scale      float64     `json:"scale"`
+	FPS        float64     `json:"fps"`
+	Sheets     []string    `json:"sheets"`
+	Animations []Animation `json:"animations"`
+}
+
+// Animation lists the frames of an animation.
+type Animation struct {
+	Name     string  `json:"name"`
+	Duration float64 `json:"duration"`
+	Frames   []Frame `json:"frames"`
+}
+
+// Frame is a trimmed frame in a sheet.
+//
+// Frames without any visible pixels have a zero size.
+type Frame struct {
+	Sheet int `json:"sheet"`
+	X     int `json:"x"`
+	Y     int `json:"y"`
+	W     int `json:"w"`
+	H     int `json:"h"`
+	// PivotX, PivotY is the skeleton origin relative to the
+	// top-left corner of the trimmed frame, y pointing down
+	PivotX   float64 `json:"pivotX"`
+	PivotY   float64 `json:"pivotY"`
+	Time     float64 `json:"time"`
+	Duration float64 `json:"duration"`
+	// Events are the events keyed between this and the next frame
+	Events []metadata.Event `json:"events,omitempty"`
+}
+
+// rendered is a trimmed frame before packing.
+type rendered struct {
+	animation, frame int
+	image            *image.RGBA
+}
+
+// Bake renders all animations of the current skin.
+func Bake(renderer *headless.Renderer, char *character.Character, name string, skeletonScale float64) error {
+	desc := Descriptor{
+		Character: name,
+		Skin:      char.Skeleton.Skin.Name,
+		Scale:     skeletonScale,
+		FPS:       *fps,
+	}
+
+	var frames []rendered
+	for animationIndex, anim := range char.Skeleton.Data.Animations {
+		char.SetAnimation(animationIndex)
+
+		duration := float64(anim.Duration)
+		times := headless.SampleTimes(duration, *fps)
+		min, max := headless.Measure(char, times)
+		viewport := headless.Fit(min, max, skeletonScale, 0)
+
+		baked := Animation{Name: anim.Name, Duration: duration}
+		for frameIndex, t := range times {
+			headless.Pose(char, t, viewport)
+			m := renderer.Render(char, viewport.Bounds, nil)
+
+			trim := Trim(m)
+			next, last := duration, true
+			if frameIndex+1 < len(times) {
+				next, last = times[frameIndex+1], false
+			}
+
+			baked.Frames = append(baked.Frames, Frame{
+				W:        trim.Dx(),
+				H:        trim.Dy(),
+				PivotX:   viewport.X - float64(trim.Min.X),
+				PivotY:   viewport.Y - float64(trim.Min.Y),
+				Time:     t,
+				Duration: next - t,
+				Events:   EventsBetween(char.Metadata.Events[anim.Name], t, next, last),
+			})
+			frames = append(frames, rendered{
+				animation: animationIndex,
+				frame:     frameIndex,
+				image:     m.SubImage(trim).(*image.RGBA),
+			})
+		}
+		desc.Animations = append(desc.Animations, baked)
+	}
+
+	sizes := make([]image.Point, len(frames))
+	for i, frame := range frames {
+		sizes[i] = frame.image.Rect.Size()
+	}
+	sheets, index, positions := Pack(sizes, *maxSize, *spacing)
+
+	images := make([]*image.RGBA, len(sheets))
+	for i, sheet := range sheets {
+		images[i] = image.NewRGBA(image.Rect(0, 0, sheet.Width, sheet.Height))
+	}
+	for i, frame := range frames {
+		baked := &desc.Animations[frame.animation].Frames[frame.frame]
+		if baked.W == 0 || baked.H == 0 {
+			continue
+		}
+
+		baked.Sheet = index[i]
+		baked.X, baked.Y = positions[i].X, positions[i].Y
+		draw.Draw(images[index[i]], frame.image.Rect.Sub(frame.image.Rect.Min).Add(positions[i]), frame.image, frame.image.Rect.Min, draw.Src)
+	}
+
+	base := sanitize.Replace(name + "-" + desc.Skin)
+	for i, m := range images {
+		sheetName := fmt.Sprintf("%v-%d.png", base, i)
+		if err := WritePNG(filepath.Join(*outputDir, sheetName), m); err != nil {
+			return err
+		}
+		desc.Sheets = append(desc.Sheets, sheetName)
+	}
+
+	data, err := json.MarshalIndent(desc, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(*outputDir, base+".json"), data, 0644)
+}
+
+// Trim returns the bounds of the non-transparent pixels of m.
+func Trim(m *image.RGBA) image.Rectangle {
+	trim := image.Rectangle{}
+	for y := m.Rect.Min.Y; y < m.Rect.Max.Y; y++ {
+		for x := m.Rect.Min.X; x < m.Rect.Max.X; x++ {
+			if m.RGBAAt(x, y).A == 0 {
+				continue
+			}
+			trim = trim.Union(image.Rect(x, y, x+1, y+1))
+		}
+	}
+	return trim
+}
+
+// EventsBetween returns events with time in [from, to), the interval
+// is closed when last is set, so events at the end of the animation
+// belong to the last frame.
+func EventsBetween(events []metadata.Event, from, to float64, last bool) []metadata.Event {
+	var result []metadata.Event
+	for _, event := range events {
+		t := float64(event.Time)
+		if t >= from && (t < to || last && t == to) {
+			result = append(result, event)
+		}
+	}
+	return result
+}
+
+var sanitize = strings.NewReplacer("/", "_", "\\", "_", ":", "_", " ", "_")
+
+// WritePNG writes m to path, creating missing directories.
+func WritePNG(path string, m image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := png.Encode(file, m); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func main() {
+	flag.Parse()
+
+	log.SetOutput(os.Stderr)
+	locations, err := animation.LoadList(animation.Assets(*assetsDir))
+	if err != nil {
+		log.Println(err)
+	}
+	if *preferBinary {
+		animation.PreferFormat(locations, animation.FormatBinary)
+	}
+	if *pma {
+		animation.EnablePMA(locations)
+	}
+
+	if *location != "" {
+		loc, ok := animation.Find(locations, *location)
+		if !ok {
+			log.Fatalf("character %q not found", *location)
+		}
+		locations = []animation.Location{loc}
+	}
+
+	renderer := headless.NewRenderer()
+	failed := 0
+	for _, loc := range locations {
+		char, err := character.LoadCharacter(loc, renderer)
+		if err != nil {
+			log.Println(loc.Name, err)
+			failed++
+			continue
+		}
+		// images have y pointing down
+		char.Skeleton.FlipY = true
+
+		skeletonScale := *scale
+		if skeletonScale <= 0 {
+			skeletonScale = loc.Scale
+		}
+
+		var selected []string
+		switch *skins {
+		case "":
+			selected = []string{char.Skeleton.Skin.Name}
+		case "all":
+			for _, skin := range char.Skeleton.Data.Skins {
+				selected = append(selected, skin.Name)
+			}
+		default:
+			selected = strings.Split(*skins, ",")
+		}
+
+		for _, skin := range selected {
+			if err := char.SelectSkin(strings.TrimSpace(skin)); err != nil {
+				log.Println(loc.Name, err)
+				failed++
+				continue
+			}
+			if err := Bake(renderer, char, loc.Name, skeletonScale); err != nil {
+				log.Println(loc.Name, skin, err)
+				failed++
+				continue
+			}
+			fmt.Printf("%v > %v\n", loc.Name, char.Skeleton.Skin.Name)
+		}
+	}
+
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"image"
+	"sort"
+)
+
+// Sheet is a packed sprite sheet.
+type Sheet struct {
+	Width, Height int
+}
+
+// Pack places rectangles of sizes into sheets of at most maxSize
+// using shelf packing, spacing pixels are kept between rectangles.
+//
+// It returns the sheet index and the position of every rectangle.
+// Rectangles larger than maxSize get a sheet of their own.
+func Pack(sizes []image.Point, maxSize, spacing int) (sheets []Sheet, index []int, positions []image.Point) {
+	index = make([]int, len(sizes))
+	positions = make([]image.Point, len(sizes))
+
+	// taller rectangles first, to keep shelves tight
+	order := make([]int, len(sizes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, k int) bool {
+		return sizes[order[i]].Y > sizes[order[k]].Y
+	})
+
+	type shelf struct {
+		y, height, x int
+	}
+	var shelves [][]shelf
+
+	for _, i := range order {
+		size := sizes[i]
+		if size.X == 0 || size.Y == 0 {
+			continue
+		}
+
+		placed := false
+		for s := range sheets {
+			if size.X > maxSize || size.Y > maxSize {
+				break
+			}
+
+			for k := range shelves[s] {
+				sh := &shelves[s][k]
+				if size.Y <= sh.height && sh.x+size.X <= maxSize {
+					index[i], positions[i] = s, image.Pt(sh.x, sh.y)
+					sh.x += size.X + spacing
+					placed = true
+					break
+				}
+			}
+			if placed {
+				break
+			}
+
+			// start a new shelf below the last one
+			y := 0
+			if n := len(shelves[s]); n > 0 {
+				last := shelves[s][n-1]
+				y = last.y + last.height + spacing
+			}
+			if y+size.Y <= maxSize {
+				shelves[s] = append(shelves[s], shelf{y: y, height: size.Y, x: size.X + spacing})
+				index[i], positions[i] = s, image.Pt(0, y)
+				placed = true
+				break
+			}
+		}
+
+		if !placed {
+			sheets = append(sheets, Sheet{})
+			shelves = append(shelves, []shelf{{y: 0, height: size.Y, x: size.X + spacing}})
+			index[i], positions[i] = len(sheets)-1, image.Pt(0, 0)
+		}
+
+		sheet := &sheets[index[i]]
+		if right := positions[i].X + size.X; right > sheet.Width {
+			sheet.Width = right
+		}
+		if bottom := positions[i].Y + size.Y; bottom > sheet.Height {
+			sheet.Height = bottom
+		}
+	}
+
+	return sheets, index, positions
+}
@@ -0,0 +1,353 @@
+// Package character implements the renderer independent part of the demos.
+//
+// Character owns the skeleton and the animation state and turns every
+// frame into textured, coloured and blended triangles, which are drawn
+// by a Renderer.
+package character
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+
+	"github.com/adinfinit/spine"
+	"github.com/adinfinit/spine-examples/animation"
+	"github.com/adinfinit/spine-examples/atlas"
+	"github.com/adinfinit/spine-examples/metadata"
+)
+
+type Character struct {
+	// State contains the animation tracks
+	State State
+	Play  bool
+	Speed float64
+	Scale float64
+
+	// Background is nil when the location doesn't specify one
+	Background color.Color
+
+	// PMA means that the atlas pages are premultiplied,
+	// they are uploaded as is and blended the same way
+	PMA   bool
+	Atlas *atlas.Atlas
+	// ImageSizes is the size of attachment images in skeleton units
+	ImageSizes map[string]metadata.Size
+	// Metadata contains the clipping attachments
+	Metadata *metadata.Skeleton
+	// Missing contains placeholders for regions not in the atlas
+	Missing map[string]*atlas.Region
+
+	Skeleton *spine.Skeleton
+
+	SkinIndex      int
+	AnimationIndex int
+
+	// Playlists are the playlists of the location,
+	// PlaylistIndex is -1 until one is played
+	Playlists     []animation.Playlist
+	PlaylistIndex int
+
+	// Debug selects the overlays returned by DebugShapes
+	Debug DebugFlags
+}
+
+// LoadCharacter loads the character at loc and uploads its atlas pages to renderer.
+func LoadCharacter(loc animation.Location, renderer Renderer) (*Character, error) {
+	data, err := loc.LoadSkeleton()
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := loc.LoadMetadata()
+	if err != nil {
+		return nil, err
+	}
+
+	char := &Character{}
+
+	char.Atlas, err = loc.LoadAtlas()
+	if err != nil {
+		return nil, err
+	}
+
+	char.PMA = loc.PMA
+	for _, page := range char.Atlas.Pages {
+		m, err := loc.LoadPage(page)
+		if err != nil {
+			return nil, err
+		}
+		if err := renderer.LoadPage(page, m, char.PMA); err != nil {
+			return nil, err
+		}
+	}
+	char.ImageSizes = meta.Images
+	char.Metadata = meta
+	char.Missing = make(map[string]*atlas.Region)
+	if err := char.loadMissing(data, renderer); err != nil {
+		return nil, err
+	}
+
+	char.Play = true
+
+	char.Speed = 1
+	char.Scale = loc.Scale
+	char.Background = loc.Background
+	char.State.Events = meta.Events
+	char.Playlists = loc.Playlists
+	char.PlaylistIndex = -1
+	char.State.Mixes.Default = loc.Mix
+	for from, targets := range loc.Mixes {
+		for to, duration := range targets {
+			char.State.Mixes.Set(from, to, duration)
+		}
+	}
+	char.Skeleton = spine.NewSkeleton(data)
+
+	skinName := loc.DefaultSkin
+	if skinName == "" {
+		skinName = data.DefaultSkin.Name
+	}
+	for i, skin := range data.Skins {
+		if skin.Name == skinName {
+			char.Skeleton.Skin = skin
+			char.SkinIndex = i
+			break
+		}
+	}
+	if char.Skeleton.Skin == nil {
+		return nil, fmt.Errorf("skin %q not found", skinName)
+	}
+
+	animationName := loc.DefaultAnimation
+	if animationName == "" {
+		animationName = data.Animations[0].Name
+	}
+	for i, animation := range data.Animations {
+		if animation.Name == animationName {
+			char.State.SetAnimation(0, animation, true)
+			char.AnimationIndex = i
+			break
+		}
+	}
+	if char.State.Current() == nil {
+		return nil, fmt.Errorf("animation %q not found", animationName)
+	}
+
+	char.Skeleton.UpdateAttachments()
+	char.Skeleton.Update()
+
+	return char, nil
+}
+
+// loadMissing creates grey placeholder regions for images missing from the atlas.
+func (char *Character) loadMissing(data *spine.SkeletonData, renderer Renderer) error {
+	var page *atlas.Page
+	for _, skin := range data.Skins {
+		for _, attachment := range skin.Attachments {
+			var name string
+			switch attachment := attachment.(type) {
+			case *spine.RegionAttachment:
+				name = imageName(attachment.Name, attachment.Path)
+			case *spine.MeshAttachment:
+				name = imageName(attachment.Name, attachment.Path)
+			default:
+				continue
+			}
+
+			if char.Atlas.Find(name) != nil || char.Missing[name] != nil {
+				continue
+			}
+			log.Printf("%v: missing image %q", data.Name, name)
+
+			if page == nil {
+				m := image.NewRGBA(image.Rect(0, 0, 10, 10))
+				for i := range m.Pix {
+					m.Pix[i] = 0x80
+				}
+
+				page = &atlas.Page{Name: "missing", Width: 10, Height: 10}
+				if err := renderer.LoadPage(page, m, true); err != nil {
+					return err
+				}
+			}
+
+			region := &atlas.Region{
+				Page: page, Name: name,
+				Width: 10, Height: 10,
+				OrigWidth: 10, OrigHeight: 10,
+				Index: -1,
+			}
+			page.Regions = append(page.Regions, region)
+			char.Missing[name] = region
+		}
+	}
+	return nil
+}
+
+func (char *Character) Description() string {
+	description := char.Skeleton.Data.Name + " > " + char.Skeleton.Skin.Name
+	if anim := char.Animation(); anim != nil {
+		description += " > " + anim.Name
+	}
+	return description
+}
+
+func (char *Character) NextAnimation(offset int) {
+	index := char.AnimationIndex + offset
+	for index < 0 {
+		index += len(char.Skeleton.Data.Animations)
+	}
+	char.SetAnimation(index % len(char.Skeleton.Data.Animations))
+}
+
+// SetAnimation plays the animation at index in the skeleton data on track 0,
+// crossfading when the mixes specify a duration for the pair.
+func (char *Character) SetAnimation(index int) {
+	char.AnimationIndex = index
+	char.State.SetAnimation(0, char.Skeleton.Data.Animations[char.AnimationIndex], true)
+}
+
+// Animation returns the animation on track 0, nil when the track is empty.
+func (char *Character) Animation() *spine.Animation {
+	if track := char.State.Current(); track != nil {
+		return track.Animation
+	}
+	return nil
+}
+
+// Time returns the time of the animation on track 0.
+func (char *Character) Time() float64 {
+	if track := char.State.Current(); track != nil {
+		return track.Time
+	}
+	return 0
+}
+
+// SetTrack plays the animation with the specified name on the track at index.
+func (char *Character) SetTrack(index int, name string, loop bool) (*Track, error) {
+	anim, err := char.FindAnimation(name)
+	if err != nil {
+		return nil, err
+	}
+	if index == 0 {
+		char.AnimationIndex = char.animationIndex(anim)
+	}
+	return char.State.SetAnimation(index, anim, loop), nil
+}
+
+// AddTrack queues the animation with the specified name on the track at index,
+// see State.AddAnimation for the meaning of delay.
+func (char *Character) AddTrack(index int, name string, loop bool, delay float64) (*Track, error) {
+	anim, err := char.FindAnimation(name)
+	if err != nil {
+		return nil, err
+	}
+	return char.State.AddAnimation(index, anim, loop, delay), nil
+}
+
+// ClearTrack stops the track at index.
+func (char *Character) ClearTrack(index int) {
+	char.State.ClearTrack(index)
+}
+
+// FindAnimation returns the animation with the specified name.
+func (char *Character) FindAnimation(name string) (*spine.Animation, error) {
+	for _, animation := range char.Skeleton.Data.Animations {
+		if animation.Name == name {
+			return animation, nil
+		}
+	}
+	return nil, fmt.Errorf("animation %q not found", name)
+}
+
+func (char *Character) animationIndex(anim *spine.Animation) int {
+	for i, animation := range char.Skeleton.Data.Animations {
+		if animation == anim {
+			return i
+		}
+	}
+	return -1
+}
+
+func (char *Character) NextSkin(offset int) {
+	index := char.SkinIndex + offset
+	for index < 0 {
+		index += len(char.Skeleton.Data.Skins)
+	}
+	char.SetSkin(index % len(char.Skeleton.Data.Skins))
+}
+
+// SetSkin switches to the skin at index in the skeleton data.
+func (char *Character) SetSkin(index int) {
+	char.SkinIndex = index
+	char.Skeleton.Skin = char.Skeleton.Data.Skins[char.SkinIndex]
+	char.Skeleton.SetToSetupPose()
+	char.Skeleton.Update()
+	char.Skeleton.UpdateAttachments()
+}
+
+// SelectSkin switches to the skin with the specified name.
+func (char *Character) SelectSkin(name string) error {
+	for i, skin := range char.Skeleton.Data.Skins {
+		if skin.Name == name {
+			char.SetSkin(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("skin %q not found", name)
+}
+
+// SelectAnimation switches to the animation with the specified name.
+func (char *Character) SelectAnimation(name string) error {
+	for i, animation := range char.Skeleton.Data.Animations {
+		if animation.Name == name {
+			char.SetAnimation(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("animation %q not found", name)
+}
+
+// Seek jumps to time t of the animation on track 0, ending its crossfade.
+func (char *Character) Seek(t float64) {
+	char.State.Seek(t)
+}
+
+// Update advances the animation by dt and places the skeleton at x, y.
+func (char *Character) Update(dt float64, x, y float64) {
+	if char.Play {
+		char.State.Advance(dt * char.Speed)
+	}
+
+	char.Skeleton.Local.Translate.Set(float32(x), float32(y))
+	char.Skeleton.Local.Scale.Set(float32(char.Scale), float32(char.Scale))
+	char.State.Apply(char.Skeleton)
+	char.Skeleton.Update()
+}
+
+// GetImage returns the region of an attachment image.
+func (char *Character) GetImage(attachment, path string) *atlas.Region {
+	name := imageName(attachment, path)
+	if region := char.Atlas.Find(name); region != nil {
+		return region
+	}
+	return char.Missing[name]
+}
+
+// ImageScale returns the scale from region pixels to attachment size.
+func (char *Character) ImageScale(attachment, path string, region *atlas.Region) (sx, sy float32) {
+	size, ok := char.ImageSizes[imageName(attachment, path)]
+	if !ok {
+		return 1, 1
+	}
+	return size.Width / float32(region.OrigWidth), size.Height / float32(region.OrigHeight)
+}
+
+// imageName returns the name of the image used by an attachment.
+func imageName(attachment, path string) string {
+	if path != "" {
+		return path
+	}
+	return attachment
+}
@@ -0,0 +1,385 @@
+package character
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/adinfinit/spine"
+	"github.com/adinfinit/spine-examples/clip"
+)
+
+// DebugFlags selects the debug overlays of a character.
+type DebugFlags uint
+
+const (
+	// DebugBones draws every bone with a line to its parent
+	DebugBones DebugFlags = 1 << iota
+	// DebugRegions draws the quads of region attachments
+	DebugRegions
+	// DebugMeshes draws the triangles of mesh attachments
+	DebugMeshes
+	// DebugHulls draws the hull of mesh attachments
+	DebugHulls
+	// DebugBoundingBoxes draws bounding box attachments
+	DebugBoundingBoxes
+	// DebugPaths draws path attachments with their control points
+	DebugPaths
+	// DebugIK draws IK chains and their targets
+	DebugIK
+	// DebugCenter draws the skeleton origin
+	DebugCenter
+
+	DebugNone DebugFlags = 0
+	DebugAll             = DebugCenter<<1 - 1
+)
+
+// DebugNames are the names of the flags in bit order.
+var DebugNames = []string{"bones", "regions", "meshes", "hulls", "bounding boxes", "paths", "ik", "center"}
+
+// String returns the names of the enabled flags.
+func (flags DebugFlags) String() string {
+	var names []string
+	for i, name := range DebugNames {
+		if flags&(1<<uint(i)) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// ParseDebugFlags parses a comma separated list of DebugNames, or "all".
+func ParseDebugFlags(s string) (DebugFlags, error) {
+	var flags DebugFlags
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		switch name {
+		case "":
+			continue
+		case "all":
+			flags |= DebugAll
+			continue
+		}
+
+		found := false
+		for i, known := range DebugNames {
+			if strings.EqualFold(name, known) {
+				flags |= 1 << uint(i)
+				found = true
+			}
+		}
+		if !found {
+			return flags, fmt.Errorf("unknown debug overlay %q", name)
+		}
+	}
+	return flags, nil
+}
+
+// Shape is a line strip or a polygon in world coordinates.
+type Shape struct {
+	// Color is a straight alpha color
+	Color  spine.Color
+	Points []spine.Vector
+	// Closed connects the last point to the first one
+	Closed bool
+	// Filled fills the polygon instead of outlining it
+	Filled bool
+}
+
+var (
+	boneColor     = spine.Color{R: 1, G: 0.4, B: 0, A: 0.8}
+	parentColor   = spine.Color{R: 1, G: 0.4, B: 0, A: 0.4}
+	regionColor   = spine.Color{R: 0, G: 0.5, B: 1, A: 0.6}
+	meshColor     = spine.Color{R: 1, G: 1, B: 0, A: 0.3}
+	hullColor     = spine.Color{R: 1, G: 0.6, B: 0, A: 0.8}
+	boundsColor   = spine.Color{R: 0, G: 1, B: 0, A: 0.8}
+	pathColor     = spine.Color{R: 1, G: 0.5, B: 0.5, A: 0.9}
+	handleColor   = spine.Color{R: 0.6, G: 0.6, B: 0.6, A: 0.6}
+	ikColor       = spine.Color{R: 0.3, G: 0.8, B: 1, A: 0.9}
+	ikTargetColor = spine.Color{R: 1, G: 0.2, B: 0.8, A: 0.9}
+	centerColor   = spine.Color{R: 0, G: 0, B: 1, A: 1}
+)
+
+// DebugShapes returns the overlays selected by char.Debug.
+//
+// Bounding boxes and paths aren't exposed by the runtime, so they are read
+// from the metadata using the name returned by attachmentName.
+func (char *Character) DebugShapes() []Shape {
+	var shapes []Shape
+	skeleton := char.Skeleton
+
+	if char.Debug&(DebugRegions|DebugMeshes|DebugHulls) != 0 {
+		for _, slot := range skeleton.Order {
+			shapes = append(shapes, char.attachmentShapes(slot)...)
+		}
+	}
+
+	if char.Debug&(DebugBoundingBoxes|DebugPaths) != 0 {
+		for _, slot := range skeleton.Order {
+			name := char.attachmentName(slot)
+			if name == "" {
+				continue
+			}
+
+			if char.Debug&DebugBoundingBoxes != 0 {
+				if box := char.Metadata.FindBoundingBox(skeleton.Skin.Name, slot.Data.Name, name); box != nil {
+					shapes = append(shapes, Shape{
+						Color:  boundsColor,
+						Points: clip.WorldVertices(skeleton, slot, box),
+						Closed: true,
+					})
+				}
+			}
+			if char.Debug&DebugPaths != 0 {
+				if path := char.Metadata.FindPath(skeleton.Skin.Name, slot.Data.Name, name); path != nil {
+					shapes = append(shapes, pathShapes(clip.WorldVertices(skeleton, slot, &path.Polygon), path.Closed)...)
+				}
+			}
+		}
+	}
+
+	if char.Debug&DebugBones != 0 {
+		for _, bone := range skeleton.Bones {
+			if bone.Parent != nil {
+				shapes = append(shapes, Shape{
+					Color: parentColor,
+					Points: []spine.Vector{
+						bone.World.Translation(),
+						bone.Parent.World.Transform(spine.Vector{X: bone.Parent.Data.Length, Y: 0}),
+					},
+				})
+			}
+
+			length := bone.Data.Length
+			if length < 10 {
+				length = 10
+			}
+			width := length * 0.1
+			shapes = append(shapes, Shape{
+				Color: boneColor,
+				Points: []spine.Vector{
+					bone.World.Transform(spine.Vector{X: length, Y: 0}),
+					bone.World.Transform(spine.Vector{X: width + width, Y: -width}),
+					bone.World.Transform(spine.Vector{X: width + width, Y: width}),
+				},
+				Closed: true,
+				Filled: true,
+			})
+		}
+	}
+
+	if char.Debug&DebugIK != 0 {
+		shapes = append(shapes, char.ikShapes()...)
+	}
+
+	if char.Debug&DebugCenter != 0 {
+		world := skeleton.World()
+		shapes = append(shapes, Shape{
+			Color:  centerColor,
+			Points: circle(world, spine.Vector{}, 20),
+			Closed: true,
+		})
+	}
+
+	return shapes
+}
+
+// attachmentShapes returns region quads, mesh wireframes and hulls of slot.
+func (char *Character) attachmentShapes(slot *spine.Slot) []Shape {
+	var shapes []Shape
+	switch attachment := slot.Attachment.(type) {
+	case *spine.RegionAttachment:
+		if char.Debug&DebugRegions == 0 {
+			return nil
+		}
+		batch := char.Triangles(slot)
+		if batch == nil {
+			return nil
+		}
+		points := make([]spine.Vector, len(batch.Vertices))
+		for i, v := range batch.Vertices {
+			points[i] = spine.Vector{X: v.X, Y: v.Y}
+		}
+		shapes = append(shapes, Shape{Color: regionColor, Points: points, Closed: true})
+
+	case *spine.MeshAttachment:
+		world := attachment.CalculateWorldVertices(char.Skeleton, slot)
+		if char.Debug&DebugMeshes != 0 {
+			for _, tri := range attachment.Triangles {
+				shapes = append(shapes, Shape{
+					Color:  meshColor,
+					Points: []spine.Vector{world[tri[0]], world[tri[1]], world[tri[2]]},
+					Closed: true,
+				})
+			}
+		}
+		if char.Debug&DebugHulls != 0 {
+			mesh := char.Metadata.FindMesh(char.Skeleton.Skin.Name, slot.Data.Name, attachment.Name)
+			if mesh != nil && mesh.Hull > 0 && mesh.Hull <= len(world) {
+				shapes = append(shapes, Shape{
+					Color:  hullColor,
+					Points: append([]spine.Vector{}, world[:mesh.Hull]...),
+					Closed: true,
+				})
+			}
+		}
+	}
+	return shapes
+}
+
+// pathShapes returns the curves of a path attachment and its handles.
+func pathShapes(vertices []spine.Vector, closed bool) []Shape {
+	// vertices are in handle, point and out handle of every point
+	count := len(vertices) / 3
+	if count < 2 && !(closed && count == 1) {
+		return nil
+	}
+
+	var shapes []Shape
+	curves := count - 1
+	if closed {
+		curves = count
+	}
+
+	curve := Shape{Color: pathColor}
+	for i := 0; i < curves; i++ {
+		next := (i + 1) % count
+		p0, c0 := vertices[i*3+1], vertices[i*3+2]
+		c1, p1 := vertices[next*3], vertices[next*3+1]
+		curve.Points = append(curve.Points, bezier(p0, c0, c1, p1, 16)...)
+	}
+	shapes = append(shapes, curve)
+
+	for i := 0; i < count; i++ {
+		in, point, out := vertices[i*3], vertices[i*3+1], vertices[i*3+2]
+		shapes = append(shapes, Shape{
+			Color:  handleColor,
+			Points: []spine.Vector{in, point, out},
+		})
+	}
+	return shapes
+}
+
+// bezier samples a cubic bezier curve with segments lines.
+func bezier(p0, c0, c1, p1 spine.Vector, segments int) []spine.Vector {
+	points := make([]spine.Vector, 0, segments+1)
+	for i := 0; i <= segments; i++ {
+		t := float32(i) / float32(segments)
+		u := 1 - t
+		a, b, c, d := u*u*u, 3*u*u*t, 3*u*t*t, t*t*t
+		points = append(points, spine.Vector{
+			X: a*p0.X + b*c0.X + c*c1.X + d*p1.X,
+			Y: a*p0.Y + b*c0.Y + c*c1.Y + d*p1.Y,
+		})
+	}
+	return points
+}
+
+// ikShapes returns the IK chains and their targets.
+func (char *Character) ikShapes() []Shape {
+	bones := map[string]*spine.Bone{}
+	for _, bone := range char.Skeleton.Bones {
+		bones[bone.Data.Name] = bone
+	}
+
+	var shapes []Shape
+	for _, ik := range char.Metadata.IKConstraints {
+		target := bones[ik.Target]
+		if target == nil {
+			continue
+		}
+
+		chain := Shape{Color: ikColor}
+		for _, name := range ik.Bones {
+			if bone := bones[name]; bone != nil {
+				chain.Points = append(chain.Points, bone.World.Translation())
+			}
+		}
+		if len(ik.Bones) > 0 {
+			if tip := bones[ik.Bones[len(ik.Bones)-1]]; tip != nil {
+				chain.Points = append(chain.Points, tip.World.Transform(spine.Vector{X: tip.Data.Length, Y: 0}))
+			}
+		}
+		if len(chain.Points) > 1 {
+			shapes = append(shapes, chain)
+		}
+
+		center := target.World.Translation()
+		shapes = append(shapes, Shape{
+			Color:  ikTargetColor,
+			Points: circle(spine.Affine{M00: 1, M11: 1, M02: center.X, M12: center.Y}, spine.Vector{}, 6),
+			Closed: true,
+		})
+		if len(chain.Points) > 0 {
+			shapes = append(shapes, Shape{
+				Color:  ikTargetColor,
+				Points: []spine.Vector{chain.Points[len(chain.Points)-1], center},
+			})
+		}
+	}
+	return shapes
+}
+
+// circle returns points of a circle with radius around center transformed by m.
+func circle(m spine.Affine, center spine.Vector, radius float32) []spine.Vector {
+	const segments = 16
+	points := make([]spine.Vector, segments)
+	for i := range points {
+		angle := 2 * math.Pi * float64(i) / segments
+		points[i] = m.Transform(spine.Vector{
+			X: center.X + radius*float32(math.Cos(angle)),
+			Y: center.Y + radius*float32(math.Sin(angle)),
+		})
+	}
+	return points
+}
+
+// DebugVertex is a corner of a debug triangle.
+type DebugVertex struct {
+	X, Y float32
+	// Color is a straight alpha color
+	Color spine.Color
+}
+
+// DebugTriangles converts shapes into triangles, lines become quads of width.
+//
+// It's used by backends that can only draw triangles.
+func DebugTriangles(shapes []Shape, width float32) (vertices []DebugVertex, indices []int) {
+	for _, shape := range shapes {
+		if shape.Filled {
+			base := len(vertices)
+			for _, p := range shape.Points {
+				vertices = append(vertices, DebugVertex{p.X, p.Y, shape.Color})
+			}
+			for _, tri := range clip.Triangulate(shape.Points) {
+				indices = append(indices, base+tri[0], base+tri[1], base+tri[2])
+			}
+			continue
+		}
+
+		n := len(shape.Points)
+		segments := n - 1
+		if shape.Closed && n > 2 {
+			segments = n
+		}
+		for i := 0; i < segments; i++ {
+			a, b := shape.Points[i], shape.Points[(i+1)%n]
+			dx, dy := b.X-a.X, b.Y-a.Y
+			length := float32(math.Hypot(float64(dx), float64(dy)))
+			if length == 0 {
+				continue
+			}
+			nx, ny := -dy/length*width*0.5, dx/length*width*0.5
+
+			base := len(vertices)
+			vertices = append(vertices,
+				DebugVertex{a.X + nx, a.Y + ny, shape.Color},
+				DebugVertex{b.X + nx, b.Y + ny, shape.Color},
+				DebugVertex{b.X - nx, b.Y - ny, shape.Color},
+				DebugVertex{a.X - nx, a.Y - ny, shape.Color},
+			)
+			indices = append(indices, base, base+1, base+2, base, base+2, base+3)
+		}
+	}
+	return vertices, indices
+}
@@ -0,0 +1,147 @@
+package character
+
+import (
+	"log"
+	"math"
+
+	"github.com/adinfinit/spine-examples/metadata"
+)
+
+// Listener receives animation events and lifecycle callbacks,
+// index is the index of the track. Any of the callbacks may be nil.
+type Listener struct {
+	// Start is called when the track becomes current
+	Start func(index int, track *Track)
+	// Interrupt is called when another track replaces it
+	Interrupt func(index int, track *Track)
+	// End is called when the track is no longer applied,
+	// after being replaced and faded out, or cleared
+	End func(index int, track *Track)
+	// Complete is called every time the animation reaches its end
+	Complete func(index int, track *Track)
+	// Event is called for every keyed event the track passes
+	Event func(index int, track *Track, event metadata.Event)
+}
+
+func (listener *Listener) start(index int, track *Track) {
+	if listener.Start != nil {
+		listener.Start(index, track)
+	}
+}
+
+func (listener *Listener) interrupt(index int, track *Track) {
+	if listener.Interrupt != nil {
+		listener.Interrupt(index, track)
+	}
+}
+
+func (listener *Listener) end(index int, track *Track) {
+	if listener.End != nil {
+		listener.End(index, track)
+	}
+}
+
+func (listener *Listener) complete(index int, track *Track) {
+	if listener.Complete != nil {
+		listener.Complete(index, track)
+	}
+}
+
+// LogEvents returns a listener that logs all callbacks prefixed with name.
+func LogEvents(name string) *Listener {
+	lifecycle := func(callback string) func(int, *Track) {
+		return func(index int, track *Track) {
+			log.Printf("%v [%d] %v: %v", name, index, track.Animation.Name, callback)
+		}
+	}
+	return &Listener{
+		Start:     lifecycle("start"),
+		Interrupt: lifecycle("interrupt"),
+		End:       lifecycle("end"),
+		Complete:  lifecycle("complete"),
+		Event: func(index int, track *Track, event metadata.Event) {
+			log.Printf("%v [%d] %v: event %v int=%d float=%v string=%q",
+				name, index, track.Animation.Name, event.Name, event.Int, event.Float, event.String)
+		},
+	}
+}
+
+// AddListener registers listener for the callbacks of all tracks.
+func (state *State) AddListener(listener *Listener) {
+	state.Listeners = append(state.Listeners, listener)
+}
+
+// RemoveListener unregisters listener.
+func (state *State) RemoveListener(listener *Listener) {
+	for i, other := range state.Listeners {
+		if other == listener {
+			state.Listeners = append(state.Listeners[:i], state.Listeners[i+1:]...)
+			return
+		}
+	}
+}
+
+// notify calls fn with the listener of the track and then the state listeners.
+func (state *State) notify(index int, track *Track, fn func(*Listener, int, *Track)) {
+	if track.Listener != nil {
+		fn(track.Listener, index, track)
+	}
+	for _, listener := range state.Listeners {
+		fn(listener, index, track)
+	}
+}
+
+// event calls the event callbacks for event.
+func (state *State) event(index int, track *Track, event metadata.Event) {
+	if track.Listener != nil && track.Listener.Event != nil {
+		track.Listener.Event(index, track, event)
+	}
+	for _, listener := range state.Listeners {
+		if listener.Event != nil {
+			listener.Event(index, track, event)
+		}
+	}
+}
+
+// dispatch fires the events keyed in [from, to) of the track time,
+// together with a completion every time the animation reaches its end.
+//
+// Looping animations wrap around, such that every loop fires its events.
+func (state *State) dispatch(index int, track *Track, from, to float64) {
+	if to <= from {
+		return
+	}
+
+	events := state.Events[track.Animation.Name]
+	duration := float64(track.Animation.Duration)
+
+	if !track.Loop || duration <= 0 {
+		if from >= duration {
+			return
+		}
+		for _, event := range events {
+			t := float64(event.Time)
+			// events at the very end fire together with the completion
+			if t >= from && (t < to || (t == duration && to >= duration)) {
+				state.event(index, track, event)
+			}
+		}
+		if from < duration && to >= duration {
+			state.notify(index, track, (*Listener).complete)
+		}
+		return
+	}
+
+	for loop := math.Floor(from / duration); loop*duration < to; loop++ {
+		start := loop * duration
+		for _, event := range events {
+			t := start + float64(event.Time)
+			if t >= from && t < to {
+				state.event(index, track, event)
+			}
+		}
+		if end := start + duration; end > from && end <= to {
+			state.notify(index, track, (*Listener).complete)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package character
+
+import (
+	"fmt"
+
+	"github.com/adinfinit/spine-examples/animation"
+)
+
+// Enqueue queues entries on the track at index after the already queued animations.
+//
+// An entry with a loop count plays that many times and holds its last frame,
+// when the last entry completes drained is called. drained is never called
+// when an entry loops forever.
+func (char *Character) Enqueue(index int, entries []animation.PlaylistEntry, drained func()) error {
+	return char.enqueue(index, entries, drained, false)
+}
+
+// PlayPlaylist replaces the animations on the track at index with playlist.
+//
+// The first entry crossfades from the current animation. It starts immediately,
+// unless it has a delay, then the current animation keeps playing for the delay.
+// On an empty track the delay is ignored.
+func (char *Character) PlayPlaylist(index int, playlist animation.Playlist, drained func()) error {
+	if len(playlist.Entries) == 0 {
+		return fmt.Errorf("playlist %q is empty", playlist.Name)
+	}
+	return char.enqueue(index, playlist.Entries, drained, true)
+}
+
+// NextPlaylist plays the next playlist of the location on track 0,
+// drained is called with the playlist when it completes and may be nil.
+func (char *Character) NextPlaylist(drained func(playlist animation.Playlist)) error {
+	if len(char.Playlists) == 0 {
+		return nil
+	}
+
+	char.PlaylistIndex = (char.PlaylistIndex + 1) % len(char.Playlists)
+	playlist := char.Playlists[char.PlaylistIndex]
+
+	var done func()
+	if drained != nil {
+		done = func() { drained(playlist) }
+	}
+	if err := char.PlayPlaylist(0, playlist, done); err != nil {
+		return fmt.Errorf("playlist %q: %v", playlist.Name, err)
+	}
+	return nil
+}
+
+// enqueue queues entries, when replace is set the first one replaces the current track.
+func (char *Character) enqueue(index int, entries []animation.PlaylistEntry, drained func(), replace bool) error {
+	for _, entry := range entries {
+		if _, err := char.FindAnimation(entry.Animation); err != nil {
+			return err
+		}
+		if entry.LoopCount() < 0 {
+			return fmt.Errorf("animation %q: invalid loop count %d", entry.Animation, entry.LoopCount())
+		}
+	}
+
+	var last *Track
+	for _, entry := range entries {
+		anim, _ := char.FindAnimation(entry.Animation)
+
+		loops, forever := entry.LoopCount(), entry.LoopCount() == 0
+		if forever {
+			loops = 1
+		}
+
+		// every loop is queued separately, such that the last one holds
+		for i := 0; i < loops; i++ {
+			switch {
+			case replace:
+				if current := char.State.Track(index); current != nil && entry.Delay > 0 {
+					// drop the queue, but keep the current animation for the delay
+					current.Next = nil
+					last = char.State.AddAnimation(index, anim, forever, 0)
+					last.Delay = current.Time + entry.Delay
+				} else {
+					last = char.State.SetAnimation(index, anim, forever)
+				}
+				if entry.Mix != nil {
+					last.MixDuration = *entry.Mix
+				}
+				replace = false
+			case i == 0:
+				// the delay is relative to the completion of the previous entry
+				previous := char.State.Last(index)
+				last = char.State.AddAnimation(index, anim, forever, 0)
+				if previous != nil {
+					last.Delay = float64(previous.Animation.Duration) + entry.Delay
+				}
+				if entry.Mix != nil {
+					last.MixDuration = *entry.Mix
+				}
+			default:
+				last = char.State.AddAnimation(index, anim, forever, 0)
+				last.MixDuration = 0
+			}
+		}
+
+		if forever {
+			return nil
+		}
+	}
+
+	if drained != nil && last != nil {
+		last.Listener = &Listener{
+			Complete: func(int, *Track) { drained() },
+		}
+	}
+	return nil
+}
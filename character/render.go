@@ -0,0 +1,232 @@
+package character
+
+import (
+	"image"
+	"math"
+
+	"github.com/adinfinit/spine"
+	"github.com/adinfinit/spine-examples/atlas"
+	"github.com/adinfinit/spine-examples/clip"
+	"github.com/adinfinit/spine-examples/metadata"
+)
+
+// Renderer draws batches of triangles, it's implemented by every backend.
+type Renderer interface {
+	// LoadPage uploads the image of an atlas page, m is premultiplied when pma is set.
+	LoadPage(page *atlas.Page, m image.Image, pma bool) error
+	// Draw draws a batch of triangles.
+	Draw(batch *Batch)
+}
+
+// Vertex is a triangle corner.
+type Vertex struct {
+	// X, Y is the position in world coordinates
+	X, Y float32
+	// U, V is the position in page pixels, y pointing down
+	U, V float32
+}
+
+// Batch contains the triangles of a single slot.
+type Batch struct {
+	Slot  *spine.Slot
+	Page  *atlas.Page
+	Blend spine.BlendMode
+	// Color is the straight alpha tint of the texture
+	Color spine.Color
+
+	Vertices []Vertex
+	Indices  []int
+}
+
+// Draw draws the skeleton with renderer.
+func (char *Character) Draw(renderer Renderer) {
+	for _, batch := range char.Batches() {
+		renderer.Draw(batch)
+	}
+}
+
+// Batches returns the triangles of the skeleton in draw order.
+func (char *Character) Batches() []*Batch {
+	var batches []*Batch
+
+	var clipper clip.Clipper
+	for _, slot := range char.Skeleton.Order {
+		if clipper.Begin(char.Skeleton, char.Metadata, slot, char.attachmentName(slot)) {
+			clipper.End(slot)
+			continue
+		}
+
+		batch := char.Triangles(slot)
+		if batch != nil && clipper.Active() {
+			batch.Vertices, batch.Indices = clipTriangles(&clipper, batch.Vertices, batch.Indices)
+		}
+		if batch != nil && len(batch.Indices) > 0 {
+			batches = append(batches, batch)
+		}
+
+		clipper.End(slot)
+	}
+
+	return batches
+}
+
+// Bounds returns the bounding box of batches in world coordinates.
+func Bounds(batches []*Batch) (min, max spine.Vector) {
+	first := true
+	for _, batch := range batches {
+		for _, v := range batch.Vertices {
+			if first {
+				min, max = spine.Vector{X: v.X, Y: v.Y}, spine.Vector{X: v.X, Y: v.Y}
+				first = false
+				continue
+			}
+			if v.X < min.X {
+				min.X = v.X
+			}
+			if v.Y < min.Y {
+				min.Y = v.Y
+			}
+			if v.X > max.X {
+				max.X = v.X
+			}
+			if v.Y > max.Y {
+				max.Y = v.Y
+			}
+		}
+	}
+	return min, max
+}
+
+// Triangles returns the triangles of the slot attachment,
+// nil when there's nothing to draw.
+//
+// Bounding box, path, point and clipping attachments are not drawn.
+func (char *Character) Triangles(slot *spine.Slot) *Batch {
+	batch := &Batch{
+		Slot:  slot,
+		Blend: slot.Data.Blend,
+		Color: slot.Color,
+	}
+
+	switch attachment := slot.Attachment.(type) {
+	case nil:
+		return nil
+	case *spine.RegionAttachment:
+		region := char.GetImage(attachment.Name, attachment.Path)
+		if region == nil {
+			return nil
+		}
+		batch.Page = region.Page
+
+		final := slot.Bone.World.Mul(attachment.Local.Affine())
+		sx, sy := char.ImageScale(attachment.Name, attachment.Path, region)
+
+		// corners of the packed rectangle in the original image, y pointing down
+		left := float32(region.OffsetX)
+		top := float32(region.OrigHeight - region.OffsetY - region.Height)
+		right := left + float32(region.Width)
+		bottom := top + float32(region.Height)
+
+		halfWidth, halfHeight := float32(region.OrigWidth)*0.5, float32(region.OrigHeight)*0.5
+		corner := func(x, y float32) Vertex {
+			// the attachment is centered at the origin, y pointing up
+			p := final.Transform(spine.Vector{
+				X: (x - halfWidth) * sx,
+				Y: (halfHeight - y) * sy,
+			})
+			u, v := region.PagePoint(float64(x), float64(y))
+			return Vertex{X: p.X, Y: p.Y, U: float32(u), V: float32(v)}
+		}
+
+		batch.Vertices = []Vertex{
+			corner(left, top),
+			corner(right, top),
+			corner(right, bottom),
+			corner(left, bottom),
+		}
+		batch.Indices = []int{0, 1, 2, 0, 2, 3}
+		return batch
+
+	case *spine.MeshAttachment:
+		region := char.GetImage(attachment.Name, attachment.Path)
+		if region == nil {
+			return nil
+		}
+		batch.Page = region.Page
+
+		worldPosition := attachment.CalculateWorldVertices(char.Skeleton, slot)
+		batch.Vertices = make([]Vertex, len(worldPosition))
+		for i, p := range worldPosition {
+			uv := attachment.UV[i]
+			u, v := region.PagePoint(
+				float64(uv.X)*float64(region.OrigWidth),
+				float64(uv.Y)*float64(region.OrigHeight))
+			batch.Vertices[i] = Vertex{X: p.X, Y: p.Y, U: float32(u), V: float32(v)}
+		}
+
+		batch.Indices = make([]int, 0, len(attachment.Triangles)*3)
+		for _, tri := range attachment.Triangles {
+			batch.Indices = append(batch.Indices, tri[0], tri[1], tri[2])
+		}
+		return batch
+
+	default:
+		return nil
+	}
+}
+
+// attachmentName returns the name of the current attachment of slot.
+//
+// The runtime only exposes region and mesh attachments, for other slots
+// the name is taken from the attachment keys of the playing tracks,
+// higher tracks first, and then from the setup pose.
+func (char *Character) attachmentName(slot *spine.Slot) string {
+	if slot.Attachment != nil {
+		return slot.Attachment.GetName()
+	}
+
+	for i := len(char.State.Tracks) - 1; i >= 0; i-- {
+		track := char.State.Tracks[i]
+		if track == nil {
+			continue
+		}
+		frames := char.Metadata.Attachments[track.Animation.Name][slot.Data.Name]
+		if name, ok := keyedAttachment(frames, track); ok {
+			return name
+		}
+	}
+	return char.Metadata.SetupAttachments[slot.Data.Name]
+}
+
+// keyedAttachment returns the attachment keyed by frames at the time of track.
+func keyedAttachment(frames []metadata.AttachmentFrame, track *Track) (string, bool) {
+	t := track.Time
+	if duration := float64(track.Animation.Duration); track.Loop && duration > 0 {
+		t = math.Mod(t, duration)
+	}
+
+	name, ok := "", false
+	for _, frame := range frames {
+		if float64(frame.Time) > t {
+			break
+		}
+		name, ok = frame.Name, true
+	}
+	return name, ok
+}
+
+// clipTriangles clips triangles with the active clipping attachment.
+func clipTriangles(clipper *clip.Clipper, vertices []Vertex, indices []int) ([]Vertex, []int) {
+	input := make([]clip.Vertex, len(vertices))
+	for i, v := range vertices {
+		input[i] = clip.Vertex(v)
+	}
+
+	clipped, indices := clipper.Clip(input, indices)
+
+	vertices = make([]Vertex, len(clipped))
+	for i, v := range clipped {
+		vertices[i] = Vertex(v)
+	}
+	return vertices, indices
+}
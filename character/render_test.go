@@ -0,0 +1,84 @@
+package character
+
+import (
+	"image"
+	"math"
+	"path"
+	"testing"
+
+	"github.com/adinfinit/spine-examples/animation"
+	"github.com/adinfinit/spine-examples/atlas"
+	"github.com/adinfinit/spine-examples/metadata"
+)
+
+type nopRenderer struct{}
+
+func (nopRenderer) LoadPage(page *atlas.Page, m image.Image, pma bool) error { return nil }
+func (nopRenderer) Draw(batch *Batch)                                        {}
+
+func TestBatchesClipping(t *testing.T) {
+	locs, err := animation.LoadList(animation.Assets("../animation"))
+	if err != nil {
+		t.Log(err)
+	}
+
+	tests := []struct {
+		skeleton  string
+		animation string
+		time      float64
+	}{
+		// the clipping attachment is in the setup pose
+		{"tank-pro.json", "drive", 0},
+		// the clipping attachment is keyed by the animation
+		{"spineboy-pro.json", "portal", 1},
+	}
+	for _, test := range tests {
+		t.Run(test.skeleton, func(t *testing.T) {
+			var loc *animation.Location
+			for i := range locs {
+				if path.Base(locs[i].JSON) == test.skeleton {
+					loc = &locs[i]
+				}
+			}
+			if loc == nil {
+				t.Fatalf("%v not found", test.skeleton)
+			}
+
+			char, err := LoadCharacter(*loc, nopRenderer{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := char.SelectAnimation(test.animation); err != nil {
+				t.Fatal(err)
+			}
+			char.Seek(test.time)
+			char.Update(0, 0, 0)
+
+			clipped := batchesArea(char.Batches())
+
+			clippings := char.Metadata.Clippings
+			char.Metadata.Clippings = map[metadata.AttachmentKey]*metadata.Clipping{}
+			unclipped := batchesArea(char.Batches())
+			char.Metadata.Clippings = clippings
+
+			if clipped <= 0 || clipped >= unclipped {
+				t.Errorf("clipped area %v, unclipped %v", clipped, unclipped)
+			}
+		})
+	}
+}
+
+// batchesArea returns the total area of the triangles in batches.
+func batchesArea(batches []*Batch) float64 {
+	var total float64
+	for _, batch := range batches {
+		for i := 0; i+2 < len(batch.Indices); i += 3 {
+			a := batch.Vertices[batch.Indices[i]]
+			b := batch.Vertices[batch.Indices[i+1]]
+			c := batch.Vertices[batch.Indices[i+2]]
+			cross := (b.X-a.X)*(c.Y-a.Y) - (c.X-a.X)*(b.Y-a.Y)
+			total += math.Abs(float64(cross)) * 0.5
+		}
+	}
+	return total
+}
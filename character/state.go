@@ -0,0 +1,399 @@
+package character
+
+import (
+	"math"
+
+	"github.com/adinfinit/spine"
+	"github.com/adinfinit/spine-examples/metadata"
+)
+
+// Mixes contains crossfade durations between animations,
+// similar to spine's AnimationStateData.
+type Mixes struct {
+	// Default is used for pairs without an override, 0 disables mixing
+	Default float64
+	Pairs   map[MixPair]float64
+}
+
+// MixPair identifies a transition between two animations,
+// an empty From is the fade in on an empty track.
+type MixPair struct {
+	From, To string
+}
+
+// Set overrides the crossfade duration from one animation to another.
+func (mixes *Mixes) Set(from, to string, duration float64) {
+	if mixes.Pairs == nil {
+		mixes.Pairs = map[MixPair]float64{}
+	}
+	mixes.Pairs[MixPair{from, to}] = duration
+}
+
+// Duration returns the crossfade duration from one animation to another.
+func (mixes *Mixes) Duration(from, to string) float64 {
+	if duration, ok := mixes.Pairs[MixPair{from, to}]; ok {
+		return duration
+	}
+	return mixes.Default
+}
+
+// Track is an animation playing on a layer of State.
+type Track struct {
+	Animation *spine.Animation
+	Time      float64
+	// TimeScale multiplies the time step of the track
+	TimeScale float64
+	// Alpha is the weight of the track over the lower tracks
+	Alpha float64
+	Loop  bool
+
+	// MixDuration is the time to fade in the track, MixTime
+	// is the time since the start of the fade
+	MixTime     float64
+	MixDuration float64
+	// Previous is the animation being faded out, nil when
+	// fading in from the lower tracks
+	Previous *Track
+
+	// Delay is the time after the start of the previous entry
+	// at which a queued track starts
+	Delay float64
+	// Next is queued to play after this one
+	Next *Track
+
+	// Listener receives the callbacks of this track only, may be nil
+	Listener *Listener
+}
+
+// Mixing returns whether the track is still fading in.
+func (track *Track) Mixing() bool {
+	return track.MixTime < track.MixDuration
+}
+
+// MixAlpha returns the progress of the fade in.
+func (track *Track) MixAlpha() float64 {
+	if track.MixDuration <= 0 {
+		return 1
+	}
+	return math.Min(track.MixTime/track.MixDuration, 1)
+}
+
+// advance advances the track and the track it's fading out by dt,
+// every track scales dt by its own TimeScale, the fade is not scaled.
+func (track *Track) advance(dt float64) {
+	track.Time += dt * track.TimeScale
+	track.MixTime += dt
+	if track.Previous != nil {
+		track.Previous.advance(dt)
+	}
+}
+
+// State is the playback state of a character.
+//
+// Animations play on tracks, higher tracks are applied over the lower
+// ones and override only the bones and slots their animation keys.
+// When switching animations on a track, the previous animation
+// keeps playing and fades out over the mix duration.
+type State struct {
+	Mixes  Mixes
+	Tracks []*Track
+
+	// Events contains the keyed events of every animation sorted by time
+	Events map[string][]metadata.Event
+	// Listeners receive the callbacks of all tracks
+	Listeners []*Listener
+
+	// base and layer are reused between frames
+	base, layer pose
+}
+
+// Track returns the track at index, nil when the track is empty.
+func (state *State) Track(index int) *Track {
+	if index < 0 || index >= len(state.Tracks) {
+		return nil
+	}
+	return state.Tracks[index]
+}
+
+// Current returns the track at index 0.
+func (state *State) Current() *Track { return state.Track(0) }
+
+// SetAnimation plays anim on the track at index, replacing any queued tracks.
+//
+// The new animation fades in over the mix duration of the pair, on an
+// empty track from the setup pose or the lower tracks using the pair with
+// an empty from animation. The returned track can be adjusted.
+func (state *State) SetAnimation(index int, anim *spine.Animation, loop bool) *Track {
+	track := &Track{
+		Animation: anim,
+		TimeScale: 1,
+		Alpha:     1,
+		Loop:      loop,
+	}
+	from := ""
+	if previous := state.Track(index); previous != nil {
+		from = previous.Animation.Name
+	}
+	track.MixDuration = state.Mixes.Duration(from, anim.Name)
+	state.Play(index, track)
+	return track
+}
+
+// Play makes track current at index, replacing any queued tracks.
+// The current track fades out over the mix duration of track.
+func (state *State) Play(index int, track *Track) {
+	for len(state.Tracks) <= index {
+		state.Tracks = append(state.Tracks, nil)
+	}
+	state.setCurrent(index, track)
+}
+
+// Last returns the last queued track at index, nil when the track is empty.
+func (state *State) Last(index int) *Track {
+	last := state.Track(index)
+	for last != nil && last.Next != nil {
+		last = last.Next
+	}
+	return last
+}
+
+// AddAnimation queues anim on the track at index after the last queued one.
+//
+// A delay <= 0 starts the animation when the previous one completes,
+// offset by delay. On an empty track it starts immediately.
+// The mix duration of the pair is used, unless changed on the returned track.
+func (state *State) AddAnimation(index int, anim *spine.Animation, loop bool, delay float64) *Track {
+	last := state.Last(index)
+	if last == nil {
+		return state.SetAnimation(index, anim, loop)
+	}
+
+	if delay <= 0 {
+		delay += float64(last.Animation.Duration)
+	}
+	track := &Track{
+		Animation: anim,
+		TimeScale: 1,
+		Alpha:     1,
+		Loop:      loop,
+		Delay:     delay,
+
+		MixDuration: state.Mixes.Duration(last.Animation.Name, anim.Name),
+	}
+	last.Next = track
+	return track
+}
+
+// ClearTrack stops the track at index and its queue.
+func (state *State) ClearTrack(index int) {
+	if index < 0 || index >= len(state.Tracks) {
+		return
+	}
+	if track := state.Tracks[index]; track != nil {
+		if track.Previous != nil {
+			state.notify(index, track.Previous, (*Listener).end)
+		}
+		state.notify(index, track, (*Listener).end)
+	}
+
+	state.Tracks[index] = nil
+	for len(state.Tracks) > 0 && state.Tracks[len(state.Tracks)-1] == nil {
+		state.Tracks = state.Tracks[:len(state.Tracks)-1]
+	}
+}
+
+// ClearTracks stops all tracks.
+func (state *State) ClearTracks() {
+	for index := range state.Tracks {
+		state.ClearTrack(index)
+	}
+	state.Tracks = nil
+}
+
+// setCurrent makes track the current track at index,
+// fading out the previously current one over the mix duration of track.
+func (state *State) setCurrent(index int, track *Track) {
+	track.MixTime = 0
+	if previous := state.Tracks[index]; previous != nil {
+		previous.Next = nil
+		state.notify(index, previous, (*Listener).interrupt)
+
+		// switching again while mixing drops the oldest animation
+		if previous.Previous != nil {
+			state.notify(index, previous.Previous, (*Listener).end)
+			previous.Previous = nil
+		}
+
+		if track.MixDuration > 0 {
+			track.Previous = previous
+		} else {
+			state.notify(index, previous, (*Listener).end)
+		}
+	}
+	state.Tracks[index] = track
+	state.notify(index, track, (*Listener).start)
+}
+
+// Seek jumps to time t of the current animation on track 0
+// and ends any crossfade, events in between are not fired.
+func (state *State) Seek(t float64) {
+	track := state.Current()
+	if track == nil {
+		return
+	}
+	track.Time = t
+	track.MixTime = track.MixDuration
+	if track.Previous != nil {
+		state.notify(0, track.Previous, (*Listener).end)
+		track.Previous = nil
+	}
+}
+
+// Mixing returns whether any track is fading in.
+func (state *State) Mixing() bool {
+	for _, track := range state.Tracks {
+		if track != nil && track.Mixing() {
+			return true
+		}
+	}
+	return false
+}
+
+// Advance advances all tracks by dt, starts queued tracks
+// and fires the events crossed on the current tracks.
+func (state *State) Advance(dt float64) {
+	for index, track := range state.Tracks {
+		if track == nil {
+			continue
+		}
+
+		from := track.Time
+		track.advance(dt)
+		if track.Previous != nil && !track.Mixing() {
+			state.notify(index, track.Previous, (*Listener).end)
+			track.Previous = nil
+		}
+
+		for next := track.Next; next != nil && track.Time >= next.Delay; next = track.Next {
+			state.dispatch(index, track, from, next.Delay)
+
+			// overflow in seconds, the tracks can have different time scales
+			overflow := track.Time - next.Delay
+			if track.TimeScale != 0 {
+				overflow /= track.TimeScale
+			}
+			state.setCurrent(index, next)
+			next.Time += overflow * next.TimeScale
+			next.MixTime += overflow
+			track, from = next, 0
+		}
+		state.dispatch(index, track, from, track.Time)
+	}
+}
+
+// Apply poses skeleton with all tracks, starting from the setup pose.
+//
+// Bone transforms and slot colors are blended, attachments switch
+// once a track has more than half of the weight.
+func (state *State) Apply(skeleton *spine.Skeleton) {
+	skeleton.SetToSetupPose()
+	skeleton.UpdateAttachments()
+
+	for _, track := range state.Tracks {
+		if track == nil {
+			continue
+		}
+
+		alpha := track.Alpha
+		if track.Previous == nil {
+			alpha *= track.MixAlpha()
+		}
+		if alpha >= 1 && track.Previous == nil {
+			apply(skeleton, track)
+			continue
+		}
+
+		state.base.capture(skeleton)
+		if track.Previous != nil {
+			apply(skeleton, track.Previous)
+			state.layer.capture(skeleton)
+			state.base.restore(skeleton)
+			apply(skeleton, track)
+			state.layer.mix(skeleton, float32(1-track.MixAlpha()))
+		} else {
+			apply(skeleton, track)
+		}
+		state.base.mix(skeleton, float32(1-alpha))
+	}
+}
+
+// apply applies the animation of track at its current time.
+func apply(skeleton *spine.Skeleton, track *Track) {
+	track.Animation.Apply(skeleton, float32(track.Time), track.Loop)
+}
+
+// pose contains the animated values of a skeleton.
+type pose struct {
+	bones       []spine.Transform
+	colors      []spine.Color
+	attachments []spine.Attachment
+}
+
+// capture stores the current pose of skeleton.
+func (pose *pose) capture(skeleton *spine.Skeleton) {
+	pose.bones = pose.bones[:0]
+	for _, bone := range skeleton.Bones {
+		pose.bones = append(pose.bones, bone.Local)
+	}
+	pose.colors = pose.colors[:0]
+	pose.attachments = pose.attachments[:0]
+	for _, slot := range skeleton.Slots {
+		pose.colors = append(pose.colors, slot.Color)
+		pose.attachments = append(pose.attachments, slot.Attachment)
+	}
+}
+
+// restore resets skeleton to the captured pose.
+func (pose *pose) restore(skeleton *spine.Skeleton) {
+	for i, bone := range skeleton.Bones {
+		bone.Local = pose.bones[i]
+	}
+	for i, slot := range skeleton.Slots {
+		slot.Color = pose.colors[i]
+		slot.Attachment = pose.attachments[i]
+	}
+}
+
+// mix blends skeleton towards the captured pose by alpha.
+func (pose *pose) mix(skeleton *spine.Skeleton, alpha float32) {
+	if alpha <= 0 {
+		return
+	}
+	for i, bone := range skeleton.Bones {
+		from, to := &pose.bones[i], &bone.Local
+		to.Translate = lerpVector(to.Translate, from.Translate, alpha)
+		to.Rotate += angleBetween(to.Rotate, from.Rotate) * alpha
+		to.Scale = lerpVector(to.Scale, from.Scale, alpha)
+		to.Shear = lerpVector(to.Shear, from.Shear, alpha)
+	}
+	for i, slot := range skeleton.Slots {
+		from, to := pose.colors[i], &slot.Color
+		to.R += (from.R - to.R) * alpha
+		to.G += (from.G - to.G) * alpha
+		to.B += (from.B - to.B) * alpha
+		to.A += (from.A - to.A) * alpha
+		if alpha > 0.5 {
+			slot.Attachment = pose.attachments[i]
+		}
+	}
+}
+
+func lerpVector(a, b spine.Vector, t float32) spine.Vector {
+	return spine.Vector{X: a.X + (b.X-a.X)*t, Y: a.Y + (b.Y-a.Y)*t}
+}
+
+// angleBetween returns the shortest rotation from a to b in radians.
+func angleBetween(a, b float32) float32 {
+	delta := math.Remainder(float64(b-a), 2*math.Pi)
+	return float32(delta)
+}
@@ -0,0 +1,122 @@
+package character
+
+import (
+	"fmt"
+	"math"
+)
+
+// FrameStep is the time step of Step, animations are usually keyed at 30 fps.
+const FrameStep = 1.0 / 30
+
+// SpeedPresets are the playback speeds selectable in the demos.
+var SpeedPresets = []float64{0.1, 0.25, 0.5, 1, 2}
+
+// TogglePlay pauses or resumes the playback.
+func (char *Character) TogglePlay() {
+	char.Play = !char.Play
+}
+
+// Step pauses the playback and moves frames by FrameStep on track 0.
+func (char *Character) Step(frames int) {
+	char.Play = false
+	t, _ := char.Progress()
+	char.Seek(char.wrap(t + float64(frames)*FrameStep))
+}
+
+// Scrub pauses the playback and jumps to fraction of the duration of track 0.
+func (char *Character) Scrub(fraction float64) {
+	_, duration := char.Progress()
+	char.Play = false
+	char.Seek(math.Max(0, math.Min(fraction, 1)) * duration)
+}
+
+// Progress returns the time in the current loop and the duration of track 0.
+func (char *Character) Progress() (t, duration float64) {
+	track := char.State.Current()
+	if track == nil {
+		return 0, 0
+	}
+	return char.wrap(track.Time), float64(track.Animation.Duration)
+}
+
+// wrap wraps t into the duration of track 0, non-looping tracks are clamped.
+func (char *Character) wrap(t float64) float64 {
+	track := char.State.Current()
+	if track == nil {
+		return 0
+	}
+
+	duration := float64(track.Animation.Duration)
+	if duration <= 0 {
+		return 0
+	}
+	if !track.Loop {
+		return math.Max(0, math.Min(t, duration))
+	}
+
+	t = math.Mod(t, duration)
+	if t < 0 {
+		t += duration
+	}
+	return t
+}
+
+// TransportDescription describes the playback state, e.g. "1.20 / 2.00s x0.5 paused".
+func (char *Character) TransportDescription() string {
+	t, duration := char.Progress()
+	description := fmt.Sprintf("%.2f / %.2fs x%v", t, duration, char.Speed)
+	if !char.Play {
+		description += " paused"
+	}
+	return description
+}
+
+// Timeline is the area of a timeline bar in screen coordinates.
+type Timeline struct {
+	X, Y, Width, Height float64
+
+	// Scrubbing is set while the mouse drags the playhead
+	Scrubbing bool
+	pressed   bool
+}
+
+// Contains returns whether x, y is over the timeline.
+func (timeline *Timeline) Contains(x, y float64) bool {
+	return x >= timeline.X && x <= timeline.X+timeline.Width &&
+		y >= timeline.Y && y <= timeline.Y+timeline.Height
+}
+
+// Fraction returns the position of x along the timeline from 0 to 1.
+func (timeline *Timeline) Fraction(x float64) float64 {
+	if timeline.Width <= 0 {
+		return 0
+	}
+	return math.Max(0, math.Min((x-timeline.X)/timeline.Width, 1))
+}
+
+// Update scrubs char while the mouse is pressed, scrubbing starts
+// only when the button is pressed over the timeline.
+func (timeline *Timeline) Update(char *Character, x, y float64, pressed bool) {
+	justPressed := pressed && !timeline.pressed
+	timeline.pressed = pressed
+
+	if !pressed {
+		timeline.Scrubbing = false
+		return
+	}
+	if justPressed && timeline.Contains(x, y) {
+		timeline.Scrubbing = true
+	}
+	if timeline.Scrubbing {
+		char.Scrub(timeline.Fraction(x))
+	}
+}
+
+// Playhead returns the x coordinate of the current time of char on the timeline.
+func (timeline *Timeline) Playhead(char *Character) float64 {
+	t, duration := char.Progress()
+	if duration <= 0 {
+		return timeline.X
+	}
+	return timeline.X + timeline.Width*t/duration
+}
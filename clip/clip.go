@@ -0,0 +1,187 @@
+// Package clip implements clipping attachments, independent of the renderer.
+//
+// Once a slot with a clipping attachment is reached, triangles of the
+// following slots are clipped against the clipping polygon until the
+// end slot of the attachment has been drawn.
+package clip
+
+import (
+	"github.com/adinfinit/spine"
+	"github.com/adinfinit/spine-examples/metadata"
+)
+
+// Vertex is a triangle corner with a texture coordinate.
+type Vertex struct {
+	X, Y float32
+	U, V float32
+}
+
+// Clipper tracks the active clipping attachment while walking the draw order.
+type Clipper struct {
+	active bool
+	end    string
+	// pieces is the clipping polygon split into counter-clockwise triangles
+	pieces [][3]spine.Vector
+}
+
+// Active returns whether triangles need to be clipped.
+func (clipper *Clipper) Active() bool { return clipper.active }
+
+// Begin starts clipping when the attachment named name on slot is
+// a clipping attachment and returns whether it did. Such slots have
+// nothing else to draw.
+//
+// The runtime doesn't expose clipping attachments, so the caller
+// resolves name from the setup pose and the attachment keys in meta.
+// The polygon is built from the setup vertices in meta, deform
+// timelines of the clipping attachment are not applied.
+func (clipper *Clipper) Begin(skeleton *spine.Skeleton, meta *metadata.Skeleton, slot *spine.Slot, name string) bool {
+	if name == "" {
+		return false
+	}
+
+	clipping := meta.FindClipping(skeleton.Skin.Name, slot.Data.Name, name)
+	if clipping == nil {
+		return false
+	}
+
+	// nested clipping is not supported, as in the official runtimes
+	if clipper.active {
+		return true
+	}
+
+	clipper.Start(WorldVertices(skeleton, slot, &clipping.Polygon), clipping.End)
+	return true
+}
+
+// Start starts clipping with polygon until the slot named end.
+func (clipper *Clipper) Start(polygon []spine.Vector, end string) {
+	clipper.active = true
+	clipper.end = end
+	clipper.pieces = clipper.pieces[:0]
+
+	for _, tri := range Triangulate(polygon) {
+		a, b, c := polygon[tri[0]], polygon[tri[1]], polygon[tri[2]]
+		if cross(a, b, c) < 0 {
+			b, c = c, b
+		}
+		clipper.pieces = append(clipper.pieces, [3]spine.Vector{a, b, c})
+	}
+}
+
+// End must be called after drawing slot, it stops clipping
+// when slot is the end slot of the clipping attachment.
+func (clipper *Clipper) End(slot *spine.Slot) {
+	if clipper.active && slot.Data.Name == clipper.end {
+		clipper.Stop()
+	}
+}
+
+// Stop stops clipping.
+func (clipper *Clipper) Stop() {
+	clipper.active = false
+	clipper.end = ""
+	clipper.pieces = clipper.pieces[:0]
+}
+
+// Clip clips triangles against the clipping polygon.
+//
+// The result contains new vertices, texture coordinates are interpolated.
+func (clipper *Clipper) Clip(vertices []Vertex, indices []int) ([]Vertex, []int) {
+	if !clipper.active {
+		return vertices, indices
+	}
+
+	var outVertices []Vertex
+	var outIndices []int
+	for i := 0; i+2 < len(indices); i += 3 {
+		a, b, c := vertices[indices[i]], vertices[indices[i+1]], vertices[indices[i+2]]
+		for _, piece := range clipper.pieces {
+			polygon := clipConvex([]Vertex{a, b, c}, piece)
+			if len(polygon) < 3 {
+				continue
+			}
+
+			base := len(outVertices)
+			outVertices = append(outVertices, polygon...)
+			for k := 1; k+1 < len(polygon); k++ {
+				outIndices = append(outIndices, base, base+k, base+k+1)
+			}
+		}
+	}
+
+	return outVertices, outIndices
+}
+
+// clipConvex clips polygon against a counter-clockwise triangle,
+// using Sutherland-Hodgman.
+func clipConvex(polygon []Vertex, piece [3]spine.Vector) []Vertex {
+	for edge := 0; edge < 3 && len(polygon) > 0; edge++ {
+		p, q := piece[edge], piece[(edge+1)%3]
+		side := func(v Vertex) float32 {
+			return (q.X-p.X)*(v.Y-p.Y) - (q.Y-p.Y)*(v.X-p.X)
+		}
+
+		out := make([]Vertex, 0, len(polygon)+3)
+		prev := polygon[len(polygon)-1]
+		prevSide := side(prev)
+		for _, next := range polygon {
+			nextSide := side(next)
+			if (prevSide >= 0) != (nextSide >= 0) {
+				t := prevSide / (prevSide - nextSide)
+				out = append(out, lerp(prev, next, t))
+			}
+			if nextSide >= 0 {
+				out = append(out, next)
+			}
+			prev, prevSide = next, nextSide
+		}
+		polygon = out
+	}
+	return polygon
+}
+
+func lerp(a, b Vertex, t float32) Vertex {
+	return Vertex{
+		X: a.X + (b.X-a.X)*t,
+		Y: a.Y + (b.Y-a.Y)*t,
+		U: a.U + (b.U-a.U)*t,
+		V: a.V + (b.V-a.V)*t,
+	}
+}
+
+// WorldVertices calculates the world position of the vertices of an attachment on slot.
+func WorldVertices(skeleton *spine.Skeleton, slot *spine.Slot, polygon *metadata.Polygon) []spine.Vector {
+	result := make([]spine.Vector, 0, polygon.VertexCount)
+	if !polygon.Weighted() {
+		world := slot.Bone.World
+		for i := 0; i+1 < len(polygon.Vertices); i += 2 {
+			result = append(result, transform(world, polygon.Vertices[i], polygon.Vertices[i+1]))
+		}
+		return result
+	}
+
+	vertices := polygon.Vertices
+	for i := 0; i < len(vertices); {
+		boneCount := int(vertices[i])
+		i++
+
+		var p spine.Vector
+		for k := 0; k < boneCount; k, i = k+1, i+4 {
+			bone := skeleton.Bones[int(vertices[i])]
+			weight := vertices[i+3]
+			v := transform(bone.World, vertices[i+1], vertices[i+2])
+			p.X += v.X * weight
+			p.Y += v.Y * weight
+		}
+		result = append(result, p)
+	}
+	return result
+}
+
+func transform(m spine.Affine, x, y float32) spine.Vector {
+	return spine.Vector{
+		X: m.M00*x + m.M01*y + m.M02,
+		Y: m.M10*x + m.M11*y + m.M12,
+	}
+}
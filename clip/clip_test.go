@@ -0,0 +1,203 @@
+package clip
+
+import (
+	"math"
+	"testing"
+
+	"github.com/adinfinit/spine"
+	"github.com/adinfinit/spine-examples/metadata"
+)
+
+func square(size float32) []spine.Vector {
+	return []spine.Vector{{X: 0, Y: 0}, {X: size, Y: 0}, {X: size, Y: size}, {X: 0, Y: size}}
+}
+
+func reversed(polygon []spine.Vector) []spine.Vector {
+	r := make([]spine.Vector, len(polygon))
+	for i, p := range polygon {
+		r[len(polygon)-1-i] = p
+	}
+	return r
+}
+
+func polygonArea(polygon []spine.Vector) float32 {
+	var area float32
+	for i := range polygon {
+		p, q := polygon[i], polygon[(i+1)%len(polygon)]
+		area += p.X*q.Y - q.X*p.Y
+	}
+	return area * 0.5
+}
+
+func TestTriangulate(t *testing.T) {
+	// arrow pointing right, concave at (1, 2)
+	arrow := []spine.Vector{{X: 0, Y: 0}, {X: 4, Y: 2}, {X: 0, Y: 4}, {X: 1, Y: 2}}
+	// "L" shape with a reflex corner at (1, 1)
+	ell := []spine.Vector{{X: 0, Y: 0}, {X: 3, Y: 0}, {X: 3, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 3}, {X: 0, Y: 3}}
+
+	tests := []struct {
+		name    string
+		polygon []spine.Vector
+	}{
+		{"triangle", []spine.Vector{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 1}}},
+		{"convex", square(10)},
+		{"convex clockwise", reversed(square(10))},
+		{"concave arrow", arrow},
+		{"concave arrow clockwise", reversed(arrow)},
+		{"concave", ell},
+		{"concave clockwise", reversed(ell)},
+	}
+
+	for _, test := range tests {
+		triangles := Triangulate(test.polygon)
+		if len(triangles) != len(test.polygon)-2 {
+			t.Errorf("%v: got %d triangles, expected %d", test.name, len(triangles), len(test.polygon)-2)
+			continue
+		}
+
+		// with the same winding as the polygon the areas only add up
+		// when the triangles don't overlap and stay inside
+		expected := polygonArea(test.polygon)
+		var total float32
+		for _, tri := range triangles {
+			area := polygonArea([]spine.Vector{test.polygon[tri[0]], test.polygon[tri[1]], test.polygon[tri[2]]})
+			if area == 0 || (area > 0) != (expected > 0) {
+				t.Errorf("%v: triangle %v has area %v, polygon %v", test.name, tri, area, expected)
+			}
+			total += area
+		}
+		if math.Abs(float64(total-expected)) > 1e-4 {
+			t.Errorf("%v: triangles cover %v, expected %v", test.name, total, expected)
+		}
+	}
+
+	if triangles := Triangulate(square(1)[:2]); triangles != nil {
+		t.Errorf("degenerate polygon: got %v", triangles)
+	}
+}
+
+func TestClip(t *testing.T) {
+	// texture coordinates follow the position, so they can be verified after interpolation
+	vertex := func(x, y float32) Vertex { return Vertex{X: x, Y: y, U: x / 20, V: 1 - y/20} }
+
+	tests := []struct {
+		name     string
+		polygon  []spine.Vector
+		triangle []Vertex
+		area     float32
+	}{
+		{"inside", square(10), []Vertex{vertex(1, 1), vertex(9, 1), vertex(1, 9)}, 32},
+		{"outside", square(10), []Vertex{vertex(11, 11), vertex(19, 11), vertex(11, 19)}, 0},
+		{"partial", square(10), []Vertex{vertex(5, 5), vertex(15, 5), vertex(5, 15)}, 25},
+		{"partial clockwise triangle", square(10), []Vertex{vertex(5, 5), vertex(5, 15), vertex(15, 5)}, 25},
+		{"partial clockwise polygon", reversed(square(10)), []Vertex{vertex(-5, 5), vertex(5, 5), vertex(5, 15)}, 25},
+		{"covering", square(10), []Vertex{vertex(-1, -1), vertex(30, -1), vertex(-1, 30)}, 100},
+	}
+
+	for _, test := range tests {
+		var clipper Clipper
+		clipper.Start(test.polygon, "end")
+
+		vertices, indices := clipper.Clip(test.triangle, []int{0, 1, 2})
+		if len(indices)%3 != 0 {
+			t.Fatalf("%v: got %d indices", test.name, len(indices))
+		}
+
+		var area float32
+		for i := 0; i < len(indices); i += 3 {
+			a, b, c := vertices[indices[i]], vertices[indices[i+1]], vertices[indices[i+2]]
+			area += float32(math.Abs(float64(polygonArea([]spine.Vector{{X: a.X, Y: a.Y}, {X: b.X, Y: b.Y}, {X: c.X, Y: c.Y}}))))
+		}
+		if math.Abs(float64(area-test.area)) > 1e-3 {
+			t.Errorf("%v: clipped area %v, expected %v", test.name, area, test.area)
+		}
+
+		for _, v := range vertices {
+			if v.X < -1e-4 || v.X > 10+1e-4 || v.Y < -1e-4 || v.Y > 10+1e-4 {
+				t.Errorf("%v: vertex outside of the clipping polygon %+v", test.name, v)
+			}
+			exp := vertex(v.X, v.Y)
+			if math.Abs(float64(v.U-exp.U)) > 1e-5 || math.Abs(float64(v.V-exp.V)) > 1e-5 {
+				t.Errorf("%v: uv not interpolated, got %+v, expected %v %v", test.name, v, exp.U, exp.V)
+			}
+		}
+	}
+}
+
+func TestClipperBeginEnd(t *testing.T) {
+	root := &spine.Bone{World: spine.Affine{M00: 1, M11: 1}}
+	offset := &spine.Bone{World: spine.Affine{M00: 1, M11: 1, M02: 100}}
+	skeleton := &spine.Skeleton{Skin: &spine.Skin{Name: "default"}}
+
+	slot := func(name string, bone *spine.Bone) *spine.Slot {
+		return &spine.Slot{Data: &spine.SlotData{Name: name}, Bone: bone}
+	}
+	body := slot("body", root)
+	empty := slot("empty", root)
+	mask := slot("mask", root)
+	nested := slot("nested", offset)
+	end := slot("end", root)
+	after := slot("after", root)
+
+	meta := &metadata.Skeleton{
+		Clippings: map[metadata.AttachmentKey]*metadata.Clipping{
+			{Skin: "default", Slot: "mask", Name: "mask"}: {
+				End:     "end",
+				Polygon: metadata.Polygon{VertexCount: 4, Vertices: []float32{0, 0, 10, 0, 10, 10, 0, 10}},
+			},
+			{Skin: "default", Slot: "nested", Name: "mask"}: {
+				End:     "nested",
+				Polygon: metadata.Polygon{VertexCount: 3, Vertices: []float32{0, 0, 1, 0, 0, 1}},
+			},
+		},
+	}
+
+	triangle := []Vertex{{X: 5, Y: 5}, {X: 15, Y: 5}, {X: 5, Y: 15}}
+	clippedArea := func(clipper *Clipper) float32 {
+		vertices, indices := clipper.Clip(triangle, []int{0, 1, 2})
+		var area float32
+		for i := 0; i < len(indices); i += 3 {
+			a, b, c := vertices[indices[i]], vertices[indices[i+1]], vertices[indices[i+2]]
+			area += polygonArea([]spine.Vector{{X: a.X, Y: a.Y}, {X: b.X, Y: b.Y}, {X: c.X, Y: c.Y}})
+		}
+		return area
+	}
+
+	var clipper Clipper
+	steps := []struct {
+		slot       *spine.Slot
+		attachment string
+		begins     bool
+		active     bool
+	}{
+		{body, "body", false, false},
+		{empty, "", false, false},
+		{mask, "mask", true, true},
+		{body, "body", false, true},
+		// nested clipping is ignored and doesn't stop at its own end slot
+		{nested, "mask", true, true},
+		{end, "end", false, false},
+		{after, "after", false, false},
+	}
+	for i, step := range steps {
+		if got := clipper.Begin(skeleton, meta, step.slot, step.attachment); got != step.begins {
+			t.Errorf("%d %v: Begin returned %v", i, step.slot.Data.Name, got)
+		}
+		if clipper.Active() && clippedArea(&clipper) != 25 {
+			t.Errorf("%d %v: clipped with the wrong polygon, area %v", i, step.slot.Data.Name, clippedArea(&clipper))
+		}
+		clipper.End(step.slot)
+		if clipper.Active() != step.active {
+			t.Errorf("%d %v: active %v, expected %v", i, step.slot.Data.Name, clipper.Active(), step.active)
+		}
+	}
+
+	clipper.Begin(skeleton, meta, mask, "mask")
+	clipper.Stop()
+	if clipper.Active() {
+		t.Errorf("active after Stop")
+	}
+	if vertices, indices := clipper.Clip(triangle, []int{0, 1, 2}); len(vertices) != 3 || len(indices) != 3 {
+		t.Errorf("inactive clipper modified triangles: %v %v", vertices, indices)
+	}
+}
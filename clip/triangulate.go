@@ -0,0 +1,79 @@
+package clip
+
+import "github.com/adinfinit/spine"
+
+// Triangulate splits a simple polygon into triangles using ear clipping.
+//
+// The polygon may be concave and use either winding.
+func Triangulate(polygon []spine.Vector) [][3]int {
+	if len(polygon) < 3 {
+		return nil
+	}
+
+	// winding of the polygon, ears have the same winding
+	var area float32
+	for i := range polygon {
+		p, q := polygon[i], polygon[(i+1)%len(polygon)]
+		area += p.X*q.Y - q.X*p.Y
+	}
+	winding := float32(1)
+	if area < 0 {
+		winding = -1
+	}
+
+	remaining := make([]int, len(polygon))
+	for i := range remaining {
+		remaining[i] = i
+	}
+
+	var triangles [][3]int
+	for len(remaining) > 3 {
+		n := len(remaining)
+		ear := -1
+		for i := range remaining {
+			a, b, c := remaining[(i+n-1)%n], remaining[i], remaining[(i+1)%n]
+			if isEar(polygon, remaining, a, b, c, winding) {
+				ear = i
+				break
+			}
+		}
+		// the polygon is degenerate or self-intersecting, cut anyway
+		if ear < 0 {
+			ear = 0
+		}
+
+		triangles = append(triangles, [3]int{remaining[(ear+n-1)%n], remaining[ear], remaining[(ear+1)%n]})
+		remaining = append(remaining[:ear], remaining[ear+1:]...)
+	}
+	triangles = append(triangles, [3]int{remaining[0], remaining[1], remaining[2]})
+
+	return triangles
+}
+
+func isEar(polygon []spine.Vector, remaining []int, a, b, c int, winding float32) bool {
+	pa, pb, pc := polygon[a], polygon[b], polygon[c]
+	if cross(pa, pb, pc)*winding <= 0 {
+		return false
+	}
+
+	for _, k := range remaining {
+		if k == a || k == b || k == c {
+			continue
+		}
+		p := polygon[k]
+		if p == pa || p == pb || p == pc {
+			continue
+		}
+		if cross(pa, pb, p)*winding >= 0 &&
+			cross(pb, pc, p)*winding >= 0 &&
+			cross(pc, pa, p)*winding >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// cross returns the z component of (b - a) x (c - a).
+func cross(a, b, c spine.Vector) float32 {
+	return (b.X-a.X)*(c.Y-a.Y) - (b.Y-a.Y)*(c.X-a.X)
+}
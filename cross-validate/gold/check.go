@@ -0,0 +1,331 @@
+package gold
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Tolerance is the largest allowed absolute difference per channel,
+// rotation and shear are in radians.
+type Tolerance struct {
+	Translate float32
+	Rotate    float32
+	Scale     float32
+	Shear     float32
+	// World applies to all elements of the world matrix
+	World float32
+	// Color applies to all channels of slot colors
+	Color float32
+	// Vertex is the allowed distance of mesh world vertices
+	Vertex float32
+	// Mix applies to the mixes of transform constraints
+	Mix float32
+}
+
+// DefaultTolerance matches the precision of the printed tables.
+var DefaultTolerance = Tolerance{
+	Translate: 0.01,
+	Rotate:    0.001,
+	Scale:     0.001,
+	Shear:     0.001,
+	World:     0.01,
+	Color:     0.001,
+	Vertex:    0.01,
+	Mix:       0.001,
+}
+
+// Failure is a bone, slot, constraint, frame or animation that differs more than allowed.
+type Failure struct {
+	Skin string
+	// Animation is empty for the setup pose
+	Animation string
+	// Frame is -1 for the setup pose and missing animations
+	Frame int
+	Time  float32
+	// Bone is empty when frames or bones are missing
+	Bone string
+	// Slot is set instead of Bone for slot differences
+	Slot string
+	// Constraint is set instead of Bone for transform constraint differences
+	Constraint string
+	// Label is set instead of Bone when a whole skin, animation
+	// or group of frames or constraints is missing, e.g. "skin missing"
+	Label string
+
+	// Channels contains the channels over tolerance
+	Channels []ChannelDelta
+	// Missing is the number of skins, frames, bones,
+	// slots or vertices without a counterpart
+	Missing int
+	// Attachment contains both attachment names when they differ
+	Attachment []string
+	// Flags contains the constraint flags that differ
+	Flags []string
+}
+
+// ChannelDelta is the difference of a single channel.
+type ChannelDelta struct {
+	Channel string
+	Delta   float32
+}
+
+func (failure *Failure) String() string {
+	animation := failure.Animation
+	if animation == "" {
+		animation = "setup"
+		if failure.Label != "" {
+			animation = "-"
+		}
+	}
+	r := failure.Skin + "\t" + animation
+	if failure.Frame >= 0 {
+		r += fmt.Sprintf("\t%d\t%.1f", failure.Frame, failure.Time)
+	} else {
+		r += "\t-\t-"
+	}
+	if failure.Label != "" {
+		r += "\t" + failure.Label
+	} else if failure.Bone != "" {
+		r += "\t" + failure.Bone
+	} else if failure.Slot != "" {
+		r += "\tslot " + failure.Slot
+	} else if failure.Constraint != "" {
+		r += "\tconstraint " + failure.Constraint
+	} else {
+		r += "\t-"
+	}
+
+	var details []string
+	if failure.Missing > 0 {
+		details = append(details, fmt.Sprintf("missing=%d", failure.Missing))
+	}
+	for _, channel := range failure.Channels {
+		details = append(details, fmt.Sprintf("%v=%.4f", channel.Channel, channel.Delta))
+	}
+	if failure.Attachment != nil {
+		details = append(details, fmt.Sprintf("attachment=%q/%q", failure.Attachment[0], failure.Attachment[1]))
+	}
+	details = append(details, failure.Flags...)
+	return r + "\t" + strings.Join(details, " ")
+}
+
+// Check returns the bones, slots and constraints of skeldiff that differ more
+// than tolerance, together with missing skins, animations, frames and bones.
+func (skeldiff *SkeletonDiff) Check(tolerance Tolerance) []Failure {
+	if skeldiff.Missing {
+		return []Failure{{Skin: skeldiff.Skin, Frame: -1, Label: "skin missing", Missing: 1}}
+	}
+
+	var failures []Failure
+	if skeldiff.ConstraintsMissing > 0 {
+		failures = append(failures, Failure{
+			Frame:   -1,
+			Label:   "constraints missing",
+			Missing: skeldiff.ConstraintsMissing,
+		})
+	}
+	for i := range skeldiff.Constraints {
+		constraint := &skeldiff.Constraints[i]
+		channels, flags := constraint.Exceeds(tolerance)
+		if len(channels) == 0 && len(flags) == 0 {
+			continue
+		}
+		failures = append(failures, Failure{
+			Frame:      -1,
+			Constraint: constraint.Name,
+			Channels:   channels,
+			Flags:      flags,
+		})
+	}
+
+	failures = append(failures, skeldiff.Setup.check("", -1, tolerance)...)
+	for i := range skeldiff.Animations {
+		anim := &skeldiff.Animations[i]
+		if anim.Missing > 0 {
+			// only frames are missing when both sides have the animation
+			label := "frames missing"
+			if len(anim.Frame) == 0 {
+				label = "animation missing"
+			}
+			failures = append(failures, Failure{
+				Animation: anim.Name,
+				Frame:     -1,
+				Label:     label,
+				Missing:   anim.Missing,
+			})
+		}
+		for frameIndex := range anim.Frame {
+			failures = append(failures, anim.Frame[frameIndex].check(anim.Name, frameIndex, tolerance)...)
+		}
+	}
+	for i := range failures {
+		failures[i].Skin = skeldiff.Skin
+	}
+	return failures
+}
+
+func (framediff *FrameDiff) check(animation string, frameIndex int, tolerance Tolerance) []Failure {
+	var failures []Failure
+	if framediff.Missing > 0 {
+		failures = append(failures, Failure{
+			Animation: animation,
+			Frame:     frameIndex,
+			Time:      framediff.Time,
+			Missing:   framediff.Missing,
+		})
+	}
+	for i := range framediff.Bones {
+		bone := &framediff.Bones[i]
+		channels := bone.Exceeds(tolerance)
+		if len(channels) == 0 {
+			continue
+		}
+		failures = append(failures, Failure{
+			Animation: animation,
+			Frame:     frameIndex,
+			Time:      framediff.Time,
+			Bone:      bone.Name,
+			Channels:  channels,
+		})
+	}
+	if framediff.SlotSummary.Missing > 0 {
+		failures = append(failures, Failure{
+			Animation: animation,
+			Frame:     frameIndex,
+			Time:      framediff.Time,
+			Slot:      "-",
+			Missing:   framediff.SlotSummary.Missing,
+		})
+	}
+	for i := range framediff.Slots {
+		slot := &framediff.Slots[i]
+		channels := slot.Exceeds(tolerance)
+		if len(channels) == 0 && !slot.AttachmentMismatch && slot.VertexMissing == 0 {
+			continue
+		}
+		failure := Failure{
+			Animation: animation,
+			Frame:     frameIndex,
+			Time:      framediff.Time,
+			Slot:      slot.Name,
+			Channels:  channels,
+			Missing:   slot.VertexMissing,
+		}
+		if slot.AttachmentMismatch {
+			failure.Attachment = slot.Attachment[:]
+		}
+		failures = append(failures, failure)
+	}
+	if framediff.ConstraintSummary.Missing > 0 {
+		failures = append(failures, Failure{
+			Animation:  animation,
+			Frame:      frameIndex,
+			Time:       framediff.Time,
+			Constraint: "-",
+			Missing:    framediff.ConstraintSummary.Missing,
+		})
+	}
+	for i := range framediff.Constraints {
+		constraint := &framediff.Constraints[i]
+		channels := constraint.Exceeds(tolerance)
+		if len(channels) == 0 {
+			continue
+		}
+		failures = append(failures, Failure{
+			Animation:  animation,
+			Frame:      frameIndex,
+			Time:       framediff.Time,
+			Constraint: constraint.Name,
+			Channels:   channels,
+		})
+	}
+	return failures
+}
+
+// Exceeds returns the mixes of constraint over tolerance.
+func (constraint *ConstraintDiff) Exceeds(tolerance Tolerance) []ChannelDelta {
+	var channels []ChannelDelta
+	check := func(channel string, delta, limit float32) {
+		if abs(delta) > limit {
+			channels = append(channels, ChannelDelta{channel, delta})
+		}
+	}
+
+	check("rotatemix", constraint.RotateMix, tolerance.Mix)
+	check("translatemix", constraint.TranslateMix, tolerance.Mix)
+	check("scalemix", constraint.ScaleMix, tolerance.Mix)
+	check("shearmix", constraint.ShearMix, tolerance.Mix)
+	return channels
+}
+
+// Exceeds returns the mixes and offsets of constraint over tolerance
+// and the flags that differ.
+func (constraint *ConstraintDataDiff) Exceeds(tolerance Tolerance) (channels []ChannelDelta, flags []string) {
+	check := func(channel string, delta, limit float32) {
+		if abs(delta) > limit {
+			channels = append(channels, ChannelDelta{channel, delta})
+		}
+	}
+
+	check("rotatemix", constraint.RotateMix, tolerance.Mix)
+	check("translatemix", constraint.TranslateMix, tolerance.Mix)
+	check("scalemix", constraint.ScaleMix, tolerance.Mix)
+	check("shearmix", constraint.ShearMix, tolerance.Mix)
+	check("offsetrotation", constraint.OffsetRotation, tolerance.Rotate)
+	check("offsetx", constraint.OffsetX, tolerance.Translate)
+	check("offsety", constraint.OffsetY, tolerance.Translate)
+	check("offsetscalex", constraint.OffsetScaleX, tolerance.Scale)
+	check("offsetscaley", constraint.OffsetScaleY, tolerance.Scale)
+	check("offsetsheary", constraint.OffsetShearY, tolerance.Shear)
+
+	if constraint.RelativeMismatch {
+		flags = append(flags, fmt.Sprintf("relative=%v/%v", constraint.Relative[0], constraint.Relative[1]))
+	}
+	if constraint.LocalMismatch {
+		flags = append(flags, fmt.Sprintf("local=%v/%v", constraint.Local[0], constraint.Local[1]))
+	}
+	return channels, flags
+}
+
+// Exceeds returns the color channels and vertices of slot over tolerance.
+func (slot *SlotDiff) Exceeds(tolerance Tolerance) []ChannelDelta {
+	var channels []ChannelDelta
+	check := func(channel string, delta, limit float32) {
+		if abs(delta) > limit {
+			channels = append(channels, ChannelDelta{channel, delta})
+		}
+	}
+
+	check("r", slot.Color.R, tolerance.Color)
+	check("g", slot.Color.G, tolerance.Color)
+	check("b", slot.Color.B, tolerance.Color)
+	check("a", slot.Color.A, tolerance.Color)
+	check("vertex", slot.VertexMax, tolerance.Vertex)
+	return channels
+}
+
+// Exceeds returns the channels of bone over tolerance.
+func (bone *Diff) Exceeds(tolerance Tolerance) []ChannelDelta {
+	var channels []ChannelDelta
+	check := func(channel string, delta, limit float32) {
+		if abs(delta) > limit {
+			channels = append(channels, ChannelDelta{channel, delta})
+		}
+	}
+
+	check("x", bone.X, tolerance.Translate)
+	check("y", bone.Y, tolerance.Translate)
+	// rotations that differ by a full turn are equivalent
+	check("rotation", diffAngle(bone.Rotation, 0), tolerance.Rotate)
+	check("scalex", bone.ScaleX, tolerance.Scale)
+	check("scaley", bone.ScaleY, tolerance.Scale)
+	check("shearx", diffAngle(bone.ShearX, 0), tolerance.Shear)
+	check("sheary", diffAngle(bone.ShearY, 0), tolerance.Shear)
+	check("a", bone.A, tolerance.World)
+	check("b", bone.B, tolerance.World)
+	check("worldx", bone.WorldX, tolerance.World)
+	check("c", bone.C, tolerance.World)
+	check("d", bone.D, tolerance.World)
+	check("worldy", bone.WorldY, tolerance.World)
+	return channels
+}
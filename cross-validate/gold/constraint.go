@@ -0,0 +1,198 @@
+package gold
+
+import "fmt"
+
+// ConstraintDataDiff is the difference of transform constraint setup data.
+type ConstraintDataDiff struct {
+	Name string
+
+	RotateMix    float32
+	TranslateMix float32
+	ScaleMix     float32
+	ShearMix     float32
+
+	OffsetRotation float32
+	OffsetX        float32
+	OffsetY        float32
+	OffsetScaleX   float32
+	OffsetScaleY   float32
+	OffsetShearY   float32
+
+	// Relative and Local contain both flags when they differ
+	Relative         [2]bool
+	RelativeMismatch bool
+	Local            [2]bool
+	LocalMismatch    bool
+}
+
+// Changed returns whether the constraint data differs noticeably.
+func (d *ConstraintDataDiff) Changed() bool {
+	const eps = 0.001
+	for _, v := range []float32{
+		d.RotateMix, d.TranslateMix, d.ScaleMix, d.ShearMix,
+		d.OffsetRotation, d.OffsetX, d.OffsetY, d.OffsetScaleX, d.OffsetScaleY, d.OffsetShearY,
+	} {
+		if abs(v) >= eps {
+			return true
+		}
+	}
+	return d.RelativeMismatch || d.LocalMismatch
+}
+
+func (d *ConstraintDataDiff) String() string {
+	r := fmt.Sprintf("mix %v %v %v %v", zero(d.RotateMix), zero(d.TranslateMix), zero(d.ScaleMix), zero(d.ShearMix))
+	r += fmt.Sprintf("  offset %v %v %v %v %v %v",
+		zero(d.OffsetRotation), zero(d.OffsetX), zero(d.OffsetY),
+		zero(d.OffsetScaleX), zero(d.OffsetScaleY), zero(d.OffsetShearY))
+	if d.RelativeMismatch {
+		r += fmt.Sprintf("  relative %v %v", d.Relative[0], d.Relative[1])
+	}
+	if d.LocalMismatch {
+		r += fmt.Sprintf("  local %v %v", d.Local[0], d.Local[1])
+	}
+	return r
+}
+
+func DiffConstraintData(a, b *TransfromConstraintData) ConstraintDataDiff {
+	if a.Name != b.Name {
+		panic("name mismatch")
+	}
+	r := ConstraintDataDiff{}
+	r.Name = a.Name
+	r.RotateMix = diff(a.RotateMix, b.RotateMix)
+	r.TranslateMix = diff(a.TranslateMix, b.TranslateMix)
+	r.ScaleMix = diff(a.ScaleMix, b.ScaleMix)
+	r.ShearMix = diff(a.ShearMix, b.ShearMix)
+
+	r.OffsetRotation = diffAngle(a.OffsetRotation, b.OffsetRotation)
+	r.OffsetX = diff(a.OffsetX, b.OffsetX)
+	r.OffsetY = diff(a.OffsetY, b.OffsetY)
+	r.OffsetScaleX = diff(a.OffsetScaleX, b.OffsetScaleX)
+	r.OffsetScaleY = diff(a.OffsetScaleY, b.OffsetScaleY)
+	r.OffsetShearY = diffAngle(a.OffsetShearY, b.OffsetShearY)
+
+	if a.Relative != b.Relative {
+		r.Relative = [2]bool{a.Relative, b.Relative}
+		r.RelativeMismatch = true
+	}
+	if a.Local != b.Local {
+		r.Local = [2]bool{a.Local, b.Local}
+		r.LocalMismatch = true
+	}
+	return r
+}
+
+// diffConstraintData compares the constraints with the same index,
+// the second result is the number of constraints without a counterpart.
+func diffConstraintData(a, b []TransfromConstraintData) ([]ConstraintDataDiff, int) {
+	n := len(a)
+	if n > len(b) {
+		n = len(b)
+	}
+
+	var diffs []ConstraintDataDiff
+	for i := 0; i < n; i++ {
+		diffs = append(diffs, DiffConstraintData(&a[i], &b[i]))
+	}
+	return diffs, len(a) - n + len(b) - n
+}
+
+// ConstraintDiff is the difference of the animated mixes of a transform constraint.
+type ConstraintDiff TransfromConstraint
+
+func (d *ConstraintDiff) String() string {
+	return fmt.Sprintf("mix %v %v %v %v", zero(d.RotateMix), zero(d.TranslateMix), zero(d.ScaleMix), zero(d.ShearMix))
+}
+
+// Changed returns whether any mix differs noticeably.
+func (d *ConstraintDiff) Changed() bool {
+	const eps = 0.001
+	return abs(d.RotateMix) >= eps || abs(d.TranslateMix) >= eps ||
+		abs(d.ScaleMix) >= eps || abs(d.ShearMix) >= eps
+}
+
+func DiffConstraints(a, b *TransfromConstraint) ConstraintDiff {
+	if a.Name != b.Name {
+		panic("name mismatch")
+	}
+	r := ConstraintDiff{}
+	r.Name = a.Name
+	r.RotateMix = diff(a.RotateMix, b.RotateMix)
+	r.TranslateMix = diff(a.TranslateMix, b.TranslateMix)
+	r.ScaleMix = diff(a.ScaleMix, b.ScaleMix)
+	r.ShearMix = diff(a.ShearMix, b.ShearMix)
+	return r
+}
+
+func (a *ConstraintDiff) Apply(b *ConstraintDiff, op func(a, b float32) float32) {
+	a.RotateMix = op(a.RotateMix, b.RotateMix)
+	a.TranslateMix = op(a.TranslateMix, b.TranslateMix)
+	a.ScaleMix = op(a.ScaleMix, b.ScaleMix)
+	a.ShearMix = op(a.ShearMix, b.ShearMix)
+}
+
+// ConstraintSummary summarizes constraint mix differences,
+// the same way as DiffSummary summarizes bones.
+type ConstraintSummary struct {
+	Avg     ConstraintDiff
+	Min     ConstraintDiff
+	Max     ConstraintDiff
+	Count   int
+	Missing int
+}
+
+func (s *ConstraintSummary) String() string {
+	return fmt.Sprintf("mix min %v %v %v %v  max %v %v %v %v",
+		zero(s.Min.RotateMix), zero(s.Min.TranslateMix), zero(s.Min.ScaleMix), zero(s.Min.ShearMix),
+		zero(s.Max.RotateMix), zero(s.Max.TranslateMix), zero(s.Max.ScaleMix), zero(s.Max.ShearMix))
+}
+
+// Changed returns whether any constraint differs noticeably.
+func (s *ConstraintSummary) Changed() bool {
+	return s.Min.Changed() || s.Max.Changed() || s.Missing > 0
+}
+
+// Average returns the average of the summarized diffs.
+func (s *ConstraintSummary) Average() ConstraintDiff {
+	avg := s.Avg
+	avg.Apply(&avg, func(a, b float32) float32 {
+		if s.Count == 0 {
+			return 0
+		}
+		return a / float32(s.Count)
+	})
+	return avg
+}
+
+func (s *ConstraintSummary) Include(d *ConstraintSummary) {
+	s.Missing += d.Missing
+	if d.Count == 0 {
+		return
+	}
+
+	s.Count += 1
+	if s.Count == 1 {
+		s.Min = d.Min
+		s.Avg = d.Average()
+		s.Max = d.Max
+		return
+	}
+	s.Min.Apply(&d.Min, min)
+
+	t := d.Average()
+	s.Avg.Apply(&t, add)
+	s.Max.Apply(&d.Max, max)
+}
+
+func (s *ConstraintSummary) Add(d *ConstraintDiff) {
+	s.Count += 1
+	if s.Count == 1 {
+		s.Min = *d
+		s.Avg = *d
+		s.Max = *d
+		return
+	}
+	s.Min.Apply(d, min)
+	s.Avg.Apply(d, add)
+	s.Max.Apply(d, max)
+}
@@ -0,0 +1,139 @@
+package gold
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// Report is the diff of a single skin of a location.
+type Report struct {
+	Location string
+	Diff     SkeletonDiff
+}
+
+// WriteJSON writes reports as an indented JSON array.
+func WriteJSON(w io.Writer, reports []Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(reports)
+}
+
+// Average returns the average of the summarized diffs.
+func (s *DiffSummary) Average() Diff {
+	avg := s.Avg
+	avg.Apply(&avg, func(a, b float32) float32 {
+		if s.Count == 0 {
+			return 0
+		}
+		return a / float32(s.Count)
+	})
+	return avg
+}
+
+// MarshalJSON writes the average instead of the internal sum.
+func (s DiffSummary) MarshalJSON() ([]byte, error) {
+	avg, lo, hi := s.Average(), s.Min, s.Max
+	// the names are of the first summarized bone
+	avg.Name, lo.Name, hi.Name = "", "", ""
+	return json.Marshal(struct {
+		Avg, Min, Max Diff
+		Count         int
+	}{avg, lo, hi, s.Count})
+}
+
+// MarshalJSON writes the average instead of the internal sum.
+func (s ConstraintSummary) MarshalJSON() ([]byte, error) {
+	avg, lo, hi := s.Average(), s.Min, s.Max
+	// the names are of the first summarized constraint
+	avg.Name, lo.Name, hi.Name = "", "", ""
+	return json.Marshal(struct {
+		Avg, Min, Max  ConstraintDiff
+		Count, Missing int
+	}{avg, lo, hi, s.Count, s.Missing})
+}
+
+// CSVHeader are the columns written by WriteCSV.
+var CSVHeader = []string{
+	"location", "skin", "animation", "frame", "time", "bone", "level", "stat", "missing", "count",
+	"x", "y", "rotation", "scalex", "scaley", "shearx", "sheary",
+	"a", "b", "worldx", "c", "d", "worldy",
+}
+
+// WriteCSV writes reports as flat rows, one per bone diff and summary statistic.
+//
+// level is "skeleton", "animation", "frame" or "bone", stat is "min", "avg"
+// or "max" for summaries and "delta" for bones. The setup pose is written
+// as animation "setup" and frame -1.
+func WriteCSV(w io.Writer, reports []Report) error {
+	out := csv.NewWriter(w)
+	if err := out.Write(CSVHeader); err != nil {
+		return err
+	}
+
+	for i := range reports {
+		report := &reports[i]
+		rows := csvRows{location: report.Location, skin: report.Diff.Skin, out: out}
+
+		rows.summary("", -1, 0, "skeleton", 0, &report.Diff.Summary)
+		rows.frame("setup", -1, &report.Diff.Setup)
+		for k := range report.Diff.Animations {
+			anim := &report.Diff.Animations[k]
+			rows.summary(anim.Name, -1, 0, "animation", anim.Missing, &anim.Summary)
+			for frameIndex := range anim.Frame {
+				rows.frame(anim.Name, frameIndex, &anim.Frame[frameIndex])
+			}
+		}
+		if rows.err != nil {
+			return rows.err
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+// csvRows writes the rows of a single location, keeping the first error.
+type csvRows struct {
+	location string
+	skin     string
+	out      *csv.Writer
+	err      error
+}
+
+func (rows *csvRows) frame(animation string, frameIndex int, frame *FrameDiff) {
+	rows.summary(animation, frameIndex, frame.Time, "frame", frame.Missing, &frame.Summary)
+	for i := range frame.Bones {
+		rows.write(animation, frameIndex, frame.Time, frame.Bones[i].Name, "bone", "delta", 0, 1, &frame.Bones[i])
+	}
+}
+
+func (rows *csvRows) summary(animation string, frameIndex int, time float32, level string, missing int, s *DiffSummary) {
+	avg := s.Average()
+	rows.write(animation, frameIndex, time, "", level, "min", missing, s.Count, &s.Min)
+	rows.write(animation, frameIndex, time, "", level, "avg", missing, s.Count, &avg)
+	rows.write(animation, frameIndex, time, "", level, "max", missing, s.Count, &s.Max)
+}
+
+func (rows *csvRows) write(animation string, frameIndex int, time float32, bone, level, stat string, missing, count int, d *Diff) {
+	if rows.err != nil {
+		return
+	}
+
+	record := []string{
+		rows.location, rows.skin, animation, strconv.Itoa(frameIndex), formatFloat(time),
+		bone, level, stat, strconv.Itoa(missing), strconv.Itoa(count),
+	}
+	for _, v := range []float32{
+		d.X, d.Y, d.Rotation, d.ScaleX, d.ScaleY, d.ShearX, d.ShearY,
+		d.A, d.B, d.WorldX, d.C, d.D, d.WorldY,
+	} {
+		record = append(record, formatFloat(v))
+	}
+	rows.err = rows.out.Write(record)
+}
+
+func formatFloat(v float32) string {
+	return strconv.FormatFloat(float64(v), 'g', -1, 32)
+}
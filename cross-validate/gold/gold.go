@@ -8,6 +8,9 @@ import (
 const StepSize = 0.1
 
 type Skeleton struct {
+	// Skin is the name of the skin used for the poses
+	Skin string
+
 	Setup      Frame
 	Animations []Animation
 
@@ -30,6 +33,16 @@ func (skeleton *Skeleton) FindAnimation(name string) *Animation {
 	return nil
 }
 
+// FindSkin returns the skeleton posed with the skin name.
+func FindSkin(skeletons []Skeleton, name string) *Skeleton {
+	for i := range skeletons {
+		if skeletons[i].Skin == name {
+			return &skeletons[i]
+		}
+	}
+	return nil
+}
+
 type Animation struct {
 	Name     string
 	Duration float32
@@ -79,6 +92,8 @@ type Slot struct {
 
 	Attachment         string
 	AttachmentVertices []float32
+	// Vertices are the world vertices of a mesh attachment as x, y pairs
+	Vertices []float32
 }
 
 type TransfromConstraint struct {
@@ -109,19 +124,31 @@ type TransfromConstraintData struct {
 }
 
 type SkeletonDiff struct {
+	Skin string
+	// Missing is set when only one side has the skin
+	Missing bool
+
 	ResetBone   [][2]string
 	UpdateOrder [][2]string
 
-	Setup      FrameDiff
-	Summary    DiffSummary
-	Animations []AnimationDiff
+	// Constraints compares the transform constraint data
+	Constraints        []ConstraintDataDiff
+	ConstraintsMissing int
+
+	Setup             FrameDiff
+	Summary           DiffSummary
+	SlotSummary       SlotSummary
+	ConstraintSummary ConstraintSummary
+	Animations        []AnimationDiff
 }
 
 type AnimationDiff struct {
-	Name    string
-	Missing int
-	Summary DiffSummary
-	Frame   []FrameDiff
+	Name              string
+	Missing           int
+	Summary           DiffSummary
+	SlotSummary       SlotSummary
+	ConstraintSummary ConstraintSummary
+	Frame             []FrameDiff
 }
 
 type FrameDiff struct {
@@ -129,6 +156,12 @@ type FrameDiff struct {
 	Missing int
 	Summary DiffSummary
 	Bones   []Diff
+
+	SlotSummary SlotSummary
+	Slots       []SlotDiff
+
+	ConstraintSummary ConstraintSummary
+	Constraints       []ConstraintDiff
 }
 
 type DiffSummary struct {
@@ -139,14 +172,6 @@ type DiffSummary struct {
 }
 
 func (s *DiffSummary) LocalWorld() string {
-	avg := s.Avg
-	avg.Apply(&avg, func(a, b float32) float32 {
-		if s.Count == 0 {
-			return 0
-		}
-		return a / float32(s.Count)
-	})
-
 	// header TX
 	r := ""
 	r += fmt.Sprintf("%v\t%v\t", zero(s.Min.X), zero(s.Max.X))
@@ -204,10 +229,31 @@ func (bone *Diff) LocalWorld() string {
 	return r
 }
 
+// DiffSkins compares the skeletons with the same skin, in the order of a.
+func DiffSkins(a, b []Skeleton) []SkeletonDiff {
+	var diffs []SkeletonDiff
+	for i := range a {
+		bskel := FindSkin(b, a[i].Skin)
+		if bskel == nil {
+			diffs = append(diffs, SkeletonDiff{Skin: a[i].Skin, Missing: true})
+			continue
+		}
+		diffs = append(diffs, DiffSkeletons(&a[i], bskel))
+	}
+	for i := range b {
+		if FindSkin(a, b[i].Skin) == nil {
+			diffs = append(diffs, SkeletonDiff{Skin: b[i].Skin, Missing: true})
+		}
+	}
+	return diffs
+}
+
 func DiffSkeletons(a, b *Skeleton) SkeletonDiff {
 	skeldiff := SkeletonDiff{}
+	skeldiff.Skin = a.Skin
 	skeldiff.ResetBone = diffStrings(a.ResetBone, b.ResetBone)
 	skeldiff.UpdateOrder = diffStrings(a.UpdateOrder, b.UpdateOrder)
+	skeldiff.Constraints, skeldiff.ConstraintsMissing = diffConstraintData(a.TransfromConstraints, b.TransfromConstraints)
 	skeldiff.Setup = DiffFrames(&a.Setup, &b.Setup)
 	for i := range a.Animations {
 		aanim := &a.Animations[i]
@@ -222,6 +268,8 @@ func DiffSkeletons(a, b *Skeleton) SkeletonDiff {
 
 		diff := DiffAnimations(aanim, banim)
 		skeldiff.Summary.Include(&diff.Summary)
+		skeldiff.SlotSummary.Include(&diff.SlotSummary)
+		skeldiff.ConstraintSummary.Include(&diff.ConstraintSummary)
 		skeldiff.Animations = append(skeldiff.Animations, diff)
 	}
 	return skeldiff
@@ -269,6 +317,8 @@ func DiffAnimations(a, b *Animation) AnimationDiff {
 	for i := 0; i < n; i++ {
 		diff := DiffFrames(&a.Frame[i], &b.Frame[i])
 		animdiff.Summary.Include(&diff.Summary)
+		animdiff.SlotSummary.Include(&diff.SlotSummary)
+		animdiff.ConstraintSummary.Include(&diff.ConstraintSummary)
 		animdiff.Frame = append(animdiff.Frame, diff)
 	}
 	return animdiff
@@ -288,6 +338,28 @@ func DiffFrames(a, b *Frame) FrameDiff {
 		framediff.Summary.Add(&diff)
 		framediff.Bones = append(framediff.Bones, diff)
 	}
+
+	n = len(a.Slots)
+	if n > len(b.Slots) {
+		n = len(b.Slots)
+	}
+	framediff.SlotSummary.Missing = len(a.Slots) - n + len(b.Slots) - n
+	for i := 0; i < n; i++ {
+		diff := DiffSlots(&a.Slots[i], &b.Slots[i])
+		framediff.SlotSummary.Add(&diff)
+		framediff.Slots = append(framediff.Slots, diff)
+	}
+
+	n = len(a.TransfromConstraints)
+	if n > len(b.TransfromConstraints) {
+		n = len(b.TransfromConstraints)
+	}
+	framediff.ConstraintSummary.Missing = len(a.TransfromConstraints) - n + len(b.TransfromConstraints) - n
+	for i := 0; i < n; i++ {
+		diff := DiffConstraints(&a.TransfromConstraints[i], &b.TransfromConstraints[i])
+		framediff.ConstraintSummary.Add(&diff)
+		framediff.Constraints = append(framediff.Constraints, diff)
+	}
 	return framediff
 }
 
@@ -333,20 +405,13 @@ func (s *DiffSummary) Include(d *DiffSummary) {
 	s.Count += 1
 	if s.Count == 1 {
 		s.Min = d.Min
-		s.Avg = d.Avg
+		s.Avg = d.Average()
 		s.Max = d.Max
 		return
 	}
 	s.Min.Apply(&d.Min, min)
 
-	t := d.Avg
-	t.Apply(&t, func(a, b float32) float32 {
-		if d.Count == 0 {
-			return 0
-		}
-		return a / float32(d.Count)
-	})
-
+	t := d.Average()
 	s.Avg.Apply(&t, add)
 	s.Max.Apply(&d.Max, max)
 }
@@ -0,0 +1,156 @@
+package gold
+
+import (
+	"fmt"
+	"math"
+)
+
+// SlotDiff is the difference of a slot between two frames.
+type SlotDiff struct {
+	Name string
+
+	Color struct{ R, G, B, A float32 }
+
+	// Attachment contains both attachment names when they differ
+	Attachment         [2]string
+	AttachmentMismatch bool
+
+	// Vertices is the distance of every world vertex of a mesh attachment
+	Vertices []float32
+	// VertexMissing is the number of vertices without a counterpart
+	VertexMissing int
+	VertexAvg     float32
+	VertexMax     float32
+}
+
+// SlotSummary summarizes slot differences.
+type SlotSummary struct {
+	// Color is the largest absolute difference per channel
+	Color struct{ R, G, B, A float32 }
+
+	AttachmentMismatches int
+
+	// Vertices is the number of compared vertices
+	Vertices      int
+	VertexMissing int
+	VertexAvg     float32
+	VertexMax     float32
+
+	// Missing is the number of slots without a counterpart
+	Missing int
+}
+
+// Changed returns whether any slot differs noticeably.
+func (s *SlotSummary) Changed() bool {
+	const eps = 0.001
+	return s.Color.R >= eps || s.Color.G >= eps || s.Color.B >= eps || s.Color.A >= eps ||
+		s.VertexMax >= eps || s.AttachmentMismatches > 0 ||
+		s.VertexMissing > 0 || s.Missing > 0
+}
+
+func (s *SlotSummary) String() string {
+	r := fmt.Sprintf("color %v %v %v %v", zero(s.Color.R), zero(s.Color.G), zero(s.Color.B), zero(s.Color.A))
+	r += fmt.Sprintf("  attachments %d", s.AttachmentMismatches)
+	r += fmt.Sprintf("  vertices avg %v max %v", zero(s.VertexAvg), zero(s.VertexMax))
+	if s.VertexMissing > 0 {
+		r += fmt.Sprintf(" missing %d", s.VertexMissing)
+	}
+	if s.Missing > 0 {
+		r += fmt.Sprintf("  missing slots %d", s.Missing)
+	}
+	return r
+}
+
+// Changed returns whether the slot differs noticeably.
+func (slot *SlotDiff) Changed() bool {
+	const eps = 0.001
+	return abs(slot.Color.R) >= eps || abs(slot.Color.G) >= eps ||
+		abs(slot.Color.B) >= eps || abs(slot.Color.A) >= eps ||
+		slot.VertexMax >= eps || slot.AttachmentMismatch || slot.VertexMissing > 0
+}
+
+func (slot *SlotDiff) String() string {
+	r := fmt.Sprintf("color %v %v %v %v", zero(slot.Color.R), zero(slot.Color.G), zero(slot.Color.B), zero(slot.Color.A))
+	if slot.AttachmentMismatch {
+		r += fmt.Sprintf("  attachment %q %q", slot.Attachment[0], slot.Attachment[1])
+	}
+	if len(slot.Vertices) > 0 || slot.VertexMissing > 0 {
+		r += fmt.Sprintf("  vertices avg %v max %v", zero(slot.VertexAvg), zero(slot.VertexMax))
+	}
+	if slot.VertexMissing > 0 {
+		r += fmt.Sprintf(" missing %d", slot.VertexMissing)
+	}
+	return r
+}
+
+func DiffSlots(a, b *Slot) SlotDiff {
+	if a.Name != b.Name {
+		panic("name mismatch")
+	}
+	r := SlotDiff{}
+	r.Name = a.Name
+	r.Color.R = diff(a.Color.R, b.Color.R)
+	r.Color.G = diff(a.Color.G, b.Color.G)
+	r.Color.B = diff(a.Color.B, b.Color.B)
+	r.Color.A = diff(a.Color.A, b.Color.A)
+
+	if a.Attachment != b.Attachment {
+		r.Attachment = [2]string{a.Attachment, b.Attachment}
+		r.AttachmentMismatch = true
+	}
+
+	n := len(a.Vertices)
+	if n > len(b.Vertices) {
+		n = len(b.Vertices)
+	}
+	n /= 2
+	r.VertexMissing = len(a.Vertices)/2 - n + len(b.Vertices)/2 - n
+	for i := 0; i < n; i++ {
+		dx := a.Vertices[i*2] - b.Vertices[i*2]
+		dy := a.Vertices[i*2+1] - b.Vertices[i*2+1]
+		distance := float32(math.Hypot(float64(dx), float64(dy)))
+		r.Vertices = append(r.Vertices, distance)
+		r.VertexAvg += distance
+		r.VertexMax = max(r.VertexMax, distance)
+	}
+	if n > 0 {
+		r.VertexAvg /= float32(n)
+	}
+	return r
+}
+
+// Add includes the slot diff d.
+func (s *SlotSummary) Add(d *SlotDiff) {
+	s.Color.R = max(s.Color.R, abs(d.Color.R))
+	s.Color.G = max(s.Color.G, abs(d.Color.G))
+	s.Color.B = max(s.Color.B, abs(d.Color.B))
+	s.Color.A = max(s.Color.A, abs(d.Color.A))
+	if d.AttachmentMismatch {
+		s.AttachmentMismatches++
+	}
+	s.addVertices(len(d.Vertices), d.VertexAvg, d.VertexMax)
+	s.VertexMissing += d.VertexMissing
+}
+
+// Include includes the summary d.
+func (s *SlotSummary) Include(d *SlotSummary) {
+	s.Color.R = max(s.Color.R, d.Color.R)
+	s.Color.G = max(s.Color.G, d.Color.G)
+	s.Color.B = max(s.Color.B, d.Color.B)
+	s.Color.A = max(s.Color.A, d.Color.A)
+	s.AttachmentMismatches += d.AttachmentMismatches
+	s.addVertices(d.Vertices, d.VertexAvg, d.VertexMax)
+	s.VertexMissing += d.VertexMissing
+	s.Missing += d.Missing
+}
+
+// addVertices includes count vertices with average avg and maximum largest.
+func (s *SlotSummary) addVertices(count int, avg, largest float32) {
+	if count == 0 {
+		return
+	}
+	total := s.Vertices + count
+	s.VertexAvg = (s.VertexAvg*float32(s.Vertices) + avg*float32(count)) / float32(total)
+	s.Vertices = total
+	s.VertexMax = max(s.VertexMax, largest)
+}
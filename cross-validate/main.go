@@ -1,10 +1,11 @@
 package main
 
 import (
-	"bytes"
+	"errors"
 	"flag"
 	"fmt"
-	"io/ioutil"
+	"io"
+	"io/fs"
 	"log"
 	"os"
 	"text/tabwriter"
@@ -15,175 +16,373 @@ import (
 	"github.com/adinfinit/spine-examples/cross-validate/spinec"
 )
 
-func ReadSpineC(loc animation.Location) (gold.Skeleton, error) {
-	atlas, err := ioutil.ReadFile(loc.Atlas)
+func ReadSpineC(loc animation.Location) ([]gold.Skeleton, error) {
+	atlas, err := fs.ReadFile(loc.FS, loc.Atlas)
 	if err != nil {
-		return gold.Skeleton{}, err
+		return nil, err
 	}
 
-	content, err := ioutil.ReadFile(loc.JSON)
+	content, err := fs.ReadFile(loc.FS, loc.Skeleton())
 	if err != nil {
-		return gold.Skeleton{}, err
+		return nil, err
 	}
 
 	var x, y, scale, rotation float32
 	scale = (float32)(*rootScale)
 
-	gskeleton, err := spinec.Gold(loc.Dir, string(atlas), string(content), x, y, scale, rotation)
+	isbinary := loc.Format == animation.FormatBinary
+	gskeletons, err := spinec.Gold(loc.Dir, string(atlas), content, isbinary, x, y, scale, rotation)
 	if err != nil {
-		return gold.Skeleton{}, err
+		return nil, err
 	}
 
-	return gskeleton, nil
+	return gskeletons, nil
 }
 
-func ReadSpineGo(loc animation.Location) (gold.Skeleton, error) {
-	content, err := ioutil.ReadFile(loc.JSON)
+func ReadSpineGo(loc animation.Location) ([]gold.Skeleton, error) {
+	skeletondata, err := loc.LoadSkeleton()
 	if err != nil {
-		return gold.Skeleton{}, err
+		return nil, err
 	}
 
-	gskeleton, err := parseSpineGo(content)
+	gskeletons, err := parseSpineGo(skeletondata)
 	if err != nil {
-		return gold.Skeleton{}, err
+		return nil, err
 	}
 
-	return gskeleton, nil
+	return gskeletons, nil
 }
 
 var (
 	printFrames     = flag.Bool("frame", false, "print frame info")
 	printBones      = flag.Bool("bone", false, "print bone info")
 	printBoth       = flag.Bool("both", false, "print both")
-	printConstraint = flag.Bool("constraint", false, "print constraint")
+	printConstraint = flag.Bool("constraint", false, "print transform constraint info")
+	printSlots      = flag.Bool("slot", false, "print slot info")
 
+	selectSkin      = flag.String("skin-", "", "select skin")
 	selectAnimation = flag.String("animation-", "", "select animation")
 	selectFrame     = flag.Int("frame-", -1, "select frame")
 	selectBone      = flag.String("bone-", "", "select bone")
+	selectSlot      = flag.String("slot-", "", "select slot")
 
 	rootScale = flag.Float64("scale", 1, "scaling factor")
+
+	assetsDir = flag.String("assets", "../animation", "animation directory")
+	binary    = flag.Bool("binary", false, "validate binary .skel exports when available")
+
+	check              = flag.Bool("check", false, "only report differences over tolerance and exit with 1 on failures")
+	toleranceTranslate = flag.Float64("tolerance-translate", float64(gold.DefaultTolerance.Translate), "allowed translation difference")
+	toleranceRotate    = flag.Float64("tolerance-rotate", float64(gold.DefaultTolerance.Rotate), "allowed rotation difference in radians")
+	toleranceScale     = flag.Float64("tolerance-scale", float64(gold.DefaultTolerance.Scale), "allowed scale difference")
+	toleranceShear     = flag.Float64("tolerance-shear", float64(gold.DefaultTolerance.Shear), "allowed shear difference in radians")
+	toleranceWorld     = flag.Float64("tolerance-world", float64(gold.DefaultTolerance.World), "allowed world matrix difference")
+	toleranceColor     = flag.Float64("tolerance-color", float64(gold.DefaultTolerance.Color), "allowed slot color difference")
+	toleranceVertex    = flag.Float64("tolerance-vertex", float64(gold.DefaultTolerance.Vertex), "allowed mesh vertex distance")
+	toleranceMix       = flag.Float64("tolerance-mix", float64(gold.DefaultTolerance.Mix), "allowed transform constraint mix difference")
+
+	jsonOutput = flag.String("json", "", "write the diffs as JSON to file")
+	csvOutput  = flag.String("csv", "", "write the diffs as CSV to file")
 )
 
 func main() {
 	flag.Parse()
 
 	log.SetOutput(os.Stderr)
-	for _, loc := range animation.LoadList("../animation") {
+	locations, err := animation.LoadList(animation.Assets(*assetsDir))
+	// skipped and ambiguous exports fail the check
+	skipped := 0
+	if err != nil {
+		log.Println(err)
+		var errs animation.ListError
+		if errors.As(err, &errs) {
+			skipped = len(errs)
+		} else {
+			skipped = 1
+		}
+	}
+	if *binary {
+		animation.PreferFormat(locations, animation.FormatBinary)
+	}
+
+	tolerance := gold.Tolerance{
+		Translate: float32(*toleranceTranslate),
+		Rotate:    float32(*toleranceRotate),
+		Scale:     float32(*toleranceScale),
+		Shear:     float32(*toleranceShear),
+		World:     float32(*toleranceWorld),
+		Color:     float32(*toleranceColor),
+		Vertex:    float32(*toleranceVertex),
+		Mix:       float32(*toleranceMix),
+	}
+
+	passed, failed := 0, skipped
+	var reports []gold.Report
+	for _, loc := range locations {
 		fmt.Println()
-		fmt.Println(loc.JSON)
+		fmt.Println(loc.Skeleton())
 
 		spinec, err := ReadSpineC(loc)
 		if err != nil {
 			log.Println("failed to read spine-c: ", err)
+			failed++
 			continue
 		}
 
 		spinego, err := ReadSpineGo(loc)
 		if err != nil {
 			log.Println("failed to read spine-go: ", err)
+			failed++
 			continue
 		}
 
-		diff := gold.DiffSkeletons(&spinec, &spinego)
+		diffs := gold.DiffSkins(spinec, spinego)
+		for _, diff := range diffs {
+			reports = append(reports, gold.Report{Location: loc.Name, Diff: diff})
+		}
+		if *check {
+			if checkLocation(loc, diffs, tolerance) {
+				passed++
+			} else {
+				failed++
+			}
+			continue
+		}
 
-		wf := new(tabwriter.Writer)
-		wf.Init(os.Stdout, 4, 8, 4, ' ', 0)
-		if len(diff.ResetBone) > 0 {
-			fmt.Fprint(wf, "reset:\tC\tGo\n")
-			for i, entry := range diff.ResetBone {
-				fmt.Fprintf(wf, "%-d\t%v\t%v\n", i, entry[0], entry[1])
+		for i := range diffs {
+			diff := &diffs[i]
+			if diff.Skin != *selectSkin && *selectSkin != "" {
+				continue
+			}
+			fmt.Println("skin:", diff.Skin)
+			if diff.Missing {
+				fmt.Println("missing in spine-c or spine-go")
+				continue
 			}
+			printDiff(gold.FindSkin(spinec, diff.Skin), gold.FindSkin(spinego, diff.Skin), diff)
+		}
+	}
+
+	if *jsonOutput != "" {
+		if err := writeReports(*jsonOutput, reports, gold.WriteJSON); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *csvOutput != "" {
+		if err := writeReports(*csvOutput, reports, gold.WriteCSV); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *check {
+		fmt.Println()
+		fmt.Printf("check: %d passed, %d failed", passed, failed)
+		if skipped > 0 {
+			fmt.Printf(", %d of them skipped by the location list", skipped)
+		}
+		fmt.Println()
+		if failed > 0 {
+			os.Exit(1)
+		}
+	}
+}
+
+// printDiff prints the tables of a single skin.
+func printDiff(spinec, spinego *gold.Skeleton, diff *gold.SkeletonDiff) {
+	wf := new(tabwriter.Writer)
+	wf.Init(os.Stdout, 4, 8, 4, ' ', 0)
+	if len(diff.ResetBone) > 0 {
+		fmt.Fprint(wf, "reset:\tC\tGo\n")
+		for i, entry := range diff.ResetBone {
+			fmt.Fprintf(wf, "%-d\t%v\t%v\n", i, entry[0], entry[1])
 		}
-		if len(diff.UpdateOrder) > 0 {
-			fmt.Fprint(wf, "order:\tC\tGo\n")
-			for i, entry := range diff.UpdateOrder {
-				fmt.Fprintf(wf, "%-d\t%v\t%v\n", i, entry[0], entry[1])
+	}
+	if len(diff.UpdateOrder) > 0 {
+		fmt.Fprint(wf, "order:\tC\tGo\n")
+		for i, entry := range diff.UpdateOrder {
+			fmt.Fprintf(wf, "%-d\t%v\t%v\n", i, entry[0], entry[1])
+		}
+	}
+	if diff.ConstraintsMissing > 0 {
+		fmt.Fprintf(wf, "constraints:\tmissing %d\n", diff.ConstraintsMissing)
+	}
+	for i := range diff.Constraints {
+		constraint := &diff.Constraints[i]
+		if constraint.Changed() {
+			fmt.Fprintf(wf, "constraint:\t%v\t%v\n", constraint.Name, constraint.String())
+		}
+	}
+	wf.Flush()
+
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 4, 8, 4, ' ', 0)
+
+	printFrame := func(anim *gold.AnimationDiff, frameIndex int, frame *gold.FrameDiff) {
+		var aframe, bframe *gold.Frame
+		if *printBoth {
+			if anim != nil && frameIndex >= 0 {
+				aanim := spinec.FindAnimation(anim.Name)
+				banim := spinego.FindAnimation(anim.Name)
+				aframe = &aanim.Frame[frameIndex]
+				bframe = &banim.Frame[frameIndex]
+			} else {
+				aframe = &spinec.Setup
+				bframe = &spinego.Setup
 			}
 		}
-		wf.Flush()
-
-		w := new(tabwriter.Writer)
-		w.Init(os.Stdout, 4, 8, 4, ' ', 0)
-
-		printFrame := func(anim *gold.AnimationDiff, frameIndex int, frame *gold.FrameDiff) {
-			var aframe, bframe *gold.Frame
-			if *printBoth {
-				if anim != nil && frameIndex >= 0 {
-					aanim := spinec.FindAnimation(anim.Name)
-					banim := spinego.FindAnimation(anim.Name)
-					aframe = &aanim.Frame[frameIndex]
-					bframe = &banim.Frame[frameIndex]
-				} else {
-					aframe = &spinec.Setup
-					bframe = &spinego.Setup
+
+		fmt.Fprintf(w, "  |\t%.1f\t%v\n", frame.Time, frame.Summary.LocalWorld())
+		if *printBoth && *printConstraint {
+			fmt.Printf("%+v\n", aframe.TransfromConstraints)
+			fmt.Printf("%+v\n", bframe.TransfromConstraints)
+			fmt.Println()
+		}
+
+		if *printBones {
+			for boneIndex, bone := range frame.Bones {
+				if bone.Name != *selectBone && *selectBone != "" {
+					continue
+				}
+				fmt.Fprintf(w, "\t%v\t%v\n", bone.Name, bone.LocalWorld())
+				if *printBoth {
+					abone := &aframe.Bones[boneIndex]
+					bbone := &bframe.Bones[boneIndex]
+					fmt.Fprintf(w, "\t\t%v\n", gold.LocalWorldCompare(abone, bbone))
 				}
 			}
+		}
 
-			fmt.Fprintf(w, "  |\t%.1f\t%v\n", frame.Time, frame.Summary.LocalWorld())
-			if *printBones && *printConstraint {
-				fmt.Printf("%+v\n", aframe.TransfromConstraints)
-				fmt.Printf("%+v\n", bframe.TransfromConstraints)
-				fmt.Println()
+		// the setup summary is printed with the setup row
+		if anim != nil && frame.SlotSummary.Changed() {
+			fmt.Fprintf(w, "\tslots\t%v\n", frame.SlotSummary.String())
+		}
+		if *printSlots {
+			for i := range frame.Slots {
+				slot := &frame.Slots[i]
+				if slot.Name != *selectSlot && *selectSlot != "" {
+					continue
+				}
+				if slot.Changed() {
+					fmt.Fprintf(w, "\t%v\t%v\n", slot.Name, slot.String())
+				}
 			}
+		}
 
-			if *printBones {
-				for boneIndex, bone := range frame.Bones {
-					if bone.Name != *selectBone && *selectBone != "" {
-						continue
-					}
-					fmt.Fprintf(w, "\t%v\t%v\n", bone.Name, bone.LocalWorld())
-					if *printBoth {
-						abone := &aframe.Bones[boneIndex]
-						bbone := &bframe.Bones[boneIndex]
-						fmt.Fprintf(w, "\t\t%v\n", gold.LocalWorldCompare(abone, bbone))
-					}
+		if anim != nil && frame.ConstraintSummary.Changed() {
+			fmt.Fprintf(w, "\tconstraints\t%v\n", frame.ConstraintSummary.String())
+		}
+		if *printConstraint {
+			for i := range frame.Constraints {
+				constraint := &frame.Constraints[i]
+				if constraint.Changed() {
+					fmt.Fprintf(w, "\t%v\t%v\n", constraint.Name, constraint.String())
 				}
 			}
 		}
+	}
 
-		fmt.Fprintf(w, "Animation\tTime\tTX\t\tTY\t\tRo\t\tSX\t\tSY\t\tHX\t\tHY\t\tA\t\tB\t\tX\t\tC\t\tD\t\tY\t\n")
-		fmt.Fprintf(w, "Setup\t-\t%v\n", diff.Setup.Summary.LocalWorld())
-		if *printFrames {
-			if "setup" == *selectAnimation || *selectAnimation == "" {
-				printFrame(nil, -1, &diff.Setup)
-			}
+	printSummaries := func(slots *gold.SlotSummary, constraints *gold.ConstraintSummary) {
+		if slots.Changed() {
+			fmt.Fprintf(w, "  slots\t-\t%v\n", slots.String())
 		}
+		if constraints.Changed() {
+			fmt.Fprintf(w, "  constraints\t-\t%v\n", constraints.String())
+		}
+	}
 
-		fmt.Fprintf(w, "Total\t-\t%v\n", diff.Summary.LocalWorld())
-		for i := range diff.Animations {
-			anim := &diff.Animations[i]
-			if anim.Name != *selectAnimation && *selectAnimation != "" {
-				continue
-			}
-			fmt.Fprintf(w, "%v\t-\t%v\n", anim.Name, anim.Summary.LocalWorld())
-			if *printFrames {
-				for frameIndex, frame := range anim.Frame {
-					if frameIndex != *selectFrame && *selectFrame >= 0 {
-						continue
-					}
-					printFrame(anim, frameIndex, &frame)
+	fmt.Fprintf(w, "Animation\tTime\tTX\t\tTY\t\tRo\t\tSX\t\tSY\t\tHX\t\tHY\t\tA\t\tB\t\tX\t\tC\t\tD\t\tY\t\n")
+	fmt.Fprintf(w, "Setup\t-\t%v\n", diff.Setup.Summary.LocalWorld())
+	printSummaries(&diff.Setup.SlotSummary, &diff.Setup.ConstraintSummary)
+	if *printFrames {
+		if "setup" == *selectAnimation || *selectAnimation == "" {
+			printFrame(nil, -1, &diff.Setup)
+		}
+	}
+
+	fmt.Fprintf(w, "Total\t-\t%v\n", diff.Summary.LocalWorld())
+	printSummaries(&diff.SlotSummary, &diff.ConstraintSummary)
+	for i := range diff.Animations {
+		anim := &diff.Animations[i]
+		if anim.Name != *selectAnimation && *selectAnimation != "" {
+			continue
+		}
+		fmt.Fprintf(w, "%v\t-\t%v\n", anim.Name, anim.Summary.LocalWorld())
+		printSummaries(&anim.SlotSummary, &anim.ConstraintSummary)
+		if *printFrames {
+			for frameIndex, frame := range anim.Frame {
+				if frameIndex != *selectFrame && *selectFrame >= 0 {
+					continue
 				}
+				printFrame(anim, frameIndex, &frame)
 			}
 		}
-		w.Flush()
 	}
+	w.Flush()
 }
 
-func parseSpineGo(content []byte) (gold.Skeleton, error) {
+// writeReports creates file and writes reports with write.
+func writeReports(file string, reports []gold.Report, write func(io.Writer, []gold.Report) error) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+
+	if err := write(f, reports); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// checkLocation prints the differences of loc over tolerance
+// and returns whether there were none.
+func checkLocation(loc animation.Location, diffs []gold.SkeletonDiff, tolerance gold.Tolerance) bool {
+	var failures []gold.Failure
+	for i := range diffs {
+		failures = append(failures, diffs[i].Check(tolerance)...)
+	}
+	if len(failures) == 0 {
+		fmt.Println("ok")
+		return true
+	}
+
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 4, 8, 2, ' ', 0)
+	fmt.Fprint(w, "Location\tSkin\tAnimation\tFrame\tTime\tBone\tOver tolerance\n")
+	for i := range failures {
+		fmt.Fprintf(w, "%v\t%v\n", loc.Name, failures[i].String())
+	}
+	w.Flush()
+	fmt.Printf("FAIL %v: %d over tolerance\n", loc.Name, len(failures))
+	return false
+}
+
+// parseSpineGo poses the skeleton with every skin and returns one gold.Skeleton per skin.
+func parseSpineGo(skeletondata *spine.SkeletonData) ([]gold.Skeleton, error) {
+	var gskeletons []gold.Skeleton
+	for _, skin := range skeletondata.Skins {
+		gskeleton, err := parseSpineGoSkin(skeletondata, skin)
+		if err != nil {
+			return nil, err
+		}
+		gskeletons = append(gskeletons, gskeleton)
+	}
+	return gskeletons, nil
+}
+
+func parseSpineGoSkin(skeletondata *spine.SkeletonData, skin *spine.Skin) (gold.Skeleton, error) {
 	gskeleton := gold.Skeleton{}
+	gskeleton.Skin = skin.Name
 	gskeleton.HasLocal = true
 	gskeleton.HasAffineWorld = true
 
-	skeletondata, err := spine.ReadJSON(bytes.NewReader(content))
-	if err != nil {
-		return gold.Skeleton{}, err
-	}
-
 	skeleton := spine.NewSkeleton(skeletondata)
+	skeleton.Skin = skin
 
 	skeleton.FlipY = true
 
 	skeleton.SetToSetupPose()
+	skeleton.UpdateAttachments()
 	scale := float32(*rootScale)
 	skeleton.Local.Scale.Set(scale, scale)
 	skeleton.Update()
@@ -238,6 +437,7 @@ func parseSpineGo(content []byte) (gold.Skeleton, error) {
 		ganimation.Duration = animation.Duration
 
 		skeleton.SetToSetupPose()
+		skeleton.UpdateAttachments()
 		skeleton.Update()
 
 		prev := float32(0.0)
@@ -278,9 +478,15 @@ func readFrame(time float32, skeleton *spine.Skeleton) gold.Frame {
 	for _, slot := range skeleton.Slots {
 		gslot := gold.Slot{}
 		gslot.Name = slot.Data.Name
+		gslot.Color.R, gslot.Color.G, gslot.Color.B, gslot.Color.A = slot.Color.R, slot.Color.G, slot.Color.B, slot.Color.A
 		if slot.Attachment != nil {
 			gslot.Attachment = slot.Attachment.GetName()
 		}
+		if mesh, ok := slot.Attachment.(*spine.MeshAttachment); ok {
+			for _, p := range mesh.CalculateWorldVertices(skeleton, slot) {
+				gslot.Vertices = append(gslot.Vertices, p.X, p.Y)
+			}
+		}
 		frame.Slots = append(frame.Slots, gslot)
 	}
 
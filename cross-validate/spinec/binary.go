@@ -0,0 +1,9 @@
+package spinec
+
+// SkeletonBinary.c is compiled separately, because its static helpers
+// conflict with the ones in SkeletonJson.c.
+
+// #cgo CFLAGS: -Ispine-c/include
+//
+// #include <spine-c/src/spine/SkeletonBinary.c>
+import "C"
@@ -61,12 +61,8 @@ import (
 //    } _spUpdate2;
 import "C"
 
-func Gold(dir string, atlasstr string, data string, x, y, scale, rotation float32) (gold.Skeleton, error) {
-	gskeleton := gold.Skeleton{}
-	gskeleton.HasLocal = true
-	gskeleton.HasAffineWorld = true
-	gskeleton.HasAppliedWorld = true
-
+// Gold poses the skeleton with every skin and returns one gold.Skeleton per skin.
+func Gold(dir string, atlasstr string, data []byte, binary bool, x, y, scale, rotation float32) ([]gold.Skeleton, error) {
 	atlasdata := C.CString(atlasstr)
 	defer C.free(unsafe.Pointer(atlasdata))
 
@@ -76,26 +72,65 @@ func Gold(dir string, atlasstr string, data string, x, y, scale, rotation float3
 	atlas := C.spAtlas_create(atlasdata, C.int(len(atlasstr)), atlasdir, nil)
 	defer C.spAtlas_dispose(atlas)
 
-	json := C.spSkeletonJson_create(atlas)
-	if json == nil {
-		return gskeleton, errors.New("unable to create skeleton json")
-	}
-	defer C.spSkeletonJson_dispose(json)
+	var skeletondata *C.spSkeletonData
+	if binary {
+		reader := C.spSkeletonBinary_create(atlas)
+		if reader == nil {
+			return nil, errors.New("unable to create skeleton binary")
+		}
+		defer C.spSkeletonBinary_dispose(reader)
 
-	if json.error != nil {
-		errmsg := C.GoString(json.error)
-		return gskeleton, errors.New("unable to create skeleton json: " + errmsg)
-	}
+		binarydata := C.CBytes(data)
+		defer C.free(binarydata)
+
+		skeletondata = C.spSkeletonBinary_readSkeletonData(reader, (*C.uchar)(binarydata), C.int(len(data)))
+		if skeletondata == nil {
+			errmsg := C.GoString(reader.error)
+			return nil, errors.New("unable to read skeleton binary: " + errmsg)
+		}
+	} else {
+		json := C.spSkeletonJson_create(atlas)
+		if json == nil {
+			return nil, errors.New("unable to create skeleton json")
+		}
+		defer C.spSkeletonJson_dispose(json)
+
+		if json.error != nil {
+			errmsg := C.GoString(json.error)
+			return nil, errors.New("unable to create skeleton json: " + errmsg)
+		}
 
-	jsondata := C.CString(data)
-	defer C.free(unsafe.Pointer(jsondata))
+		jsondata := C.CString(string(data))
+		defer C.free(unsafe.Pointer(jsondata))
 
-	skeletondata := C.spSkeletonJson_readSkeletonData(json, jsondata)
+		skeletondata = C.spSkeletonJson_readSkeletonData(json, jsondata)
+		if skeletondata == nil {
+			errmsg := C.GoString(json.error)
+			return nil, errors.New("unable to read skeleton json: " + errmsg)
+		}
+	}
 	defer C.spSkeletonData_dispose(skeletondata)
 
+	var gskeletons []gold.Skeleton
+	for i := 0; i < int(skeletondata.skinsCount); i++ {
+		skin := *(**C.spSkin)(unsafe.Pointer((uintptr(unsafe.Pointer(skeletondata.skins)) + uintptr(i)*unsafe.Sizeof((*C.spSkin)(nil)))))
+		gskeletons = append(gskeletons, goldSkin(skeletondata, skin, x, y, scale, rotation))
+	}
+	return gskeletons, nil
+}
+
+// goldSkin poses a new skeleton using skin.
+func goldSkin(skeletondata *C.spSkeletonData, skin *C.spSkin, x, y, scale, rotation float32) gold.Skeleton {
+	gskeleton := gold.Skeleton{}
+	gskeleton.Skin = C.GoString(skin.name)
+	gskeleton.HasLocal = true
+	gskeleton.HasAffineWorld = true
+	gskeleton.HasAppliedWorld = true
+
 	skeleton := C.spSkeleton_create(skeletondata)
 	defer C.spSkeleton_dispose(skeleton)
 
+	C.spSkeleton_setSkin(skeleton, skin)
 	skeleton.flipY = 1
 	skeleton.x = (C.float)(x)
 	skeleton.y = (C.float)(y)
@@ -179,7 +214,7 @@ func Gold(dir string, atlasstr string, data string, x, y, scale, rotation float3
 		gskeleton.Animations = append(gskeleton.Animations, ganimation)
 	}
 
-	return gskeleton, nil
+	return gskeleton
 }
 
 func readFrame(time float32, skeleton *C.spSkeleton) gold.Frame {
@@ -231,6 +266,13 @@ func readFrame(time float32, skeleton *C.spSkeleton) gold.Frame {
 			value := *(*float32)(unsafe.Pointer((uintptr(unsafe.Pointer(slot.attachmentVertices)) + uintptr(k)*unsafe.Sizeof(C.float(0)))))
 			gslot.AttachmentVertices[k] = value
 		}
+		if slot.attachment != nil && slot.attachment._type == C.SP_ATTACHMENT_MESH {
+			vertices := (*C.spVertexAttachment)(unsafe.Pointer(slot.attachment))
+			gslot.Vertices = make([]float32, vertices.worldVerticesLength)
+			if len(gslot.Vertices) > 0 {
+				C.spVertexAttachment_computeWorldVertices(vertices, slot, 0, vertices.worldVerticesLength, (*C.float)(unsafe.Pointer(&gslot.Vertices[0])), 0, 2)
+			}
+		}
 		frame.Slots = append(frame.Slots, gslot)
 	}
 
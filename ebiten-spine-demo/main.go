@@ -1,11 +1,13 @@
 package main
 
 import (
+	"flag"
+	"image/color"
 	_ "image/png"
 	"log"
 
-	"github.com/adinfinit/spine"
 	"github.com/adinfinit/spine-examples/animation"
+	"github.com/adinfinit/spine-examples/character"
 
 	"github.com/hajimehoshi/ebiten"
 	"github.com/hajimehoshi/ebiten/ebitenutil"
@@ -19,33 +21,53 @@ const (
 )
 
 var (
-	characters     []*Character
-	character      *Character
+	assetsDir = flag.String("assets", "../animation", "animation directory")
+	binary    = flag.Bool("binary", false, "load binary .skel exports when available")
+	pma       = flag.Bool("pma", false, "use premultiplied alpha atlases when available")
+	mix       = flag.Float64("mix", -1, "default crossfade duration in seconds, negative uses the manifest")
+	events    = flag.Bool("events", false, "print animation events")
+
+	renderer       = NewRenderer()
+	characters     []*character.Character
+	char           *character.Character
 	characterIndex int
+
+	timeline = character.Timeline{X: 10, Y: screenHeight - 20, Width: screenWidth - 20, Height: 10}
 )
 
 func main() {
-skip:
-	for _, loc := range animation.LoadList("../animation") {
-		character, err := LoadCharacter(loc)
+	flag.Parse()
+
+	locations, err := animation.LoadList(animation.Assets(*assetsDir))
+	if err != nil {
+		log.Println(err)
+	}
+	if *binary {
+		animation.PreferFormat(locations, animation.FormatBinary)
+	}
+	if *pma {
+		animation.EnablePMA(locations)
+	}
+
+	for _, loc := range locations {
+		char, err := character.LoadCharacter(loc, renderer)
 		if err != nil {
 			log.Println(loc.Name, err)
 			continue
 		}
-
-		for _, skin := range character.Skeleton.Data.Skins {
-			for _, att := range skin.Attachments {
-				if _, ismesh := att.(*spine.MeshAttachment); ismesh {
-					log.Println(loc.Name, "Unsupported")
-					continue skip
-				}
-			}
+		// ebiten has y pointing down
+		char.Skeleton.FlipY = true
+		if *mix >= 0 {
+			char.State.Mixes.Default = *mix
+		}
+		if *events {
+			char.State.AddListener(character.LogEvents(loc.Name))
 		}
 
-		characters = append(characters, character)
+		characters = append(characters, char)
 	}
 
-	character = characters[0]
+	char = characters[0]
 	if err := ebiten.Run(update, screenWidth, screenHeight, 1, "Spine (Ebiten Demo)"); err != nil {
 		panic(err)
 	}
@@ -54,38 +76,80 @@ skip:
 func update(screen *ebiten.Image) error {
 	if inpututil.IsKeyJustPressed(ebiten.KeyUp) {
 		characterIndex = (characterIndex + len(characters) - 1) % len(characters)
-		character = characters[characterIndex]
+		char = characters[characterIndex]
 	}
 	if inpututil.IsKeyJustPressed(ebiten.KeyDown) {
 		characterIndex = (characterIndex + len(characters) + 1) % len(characters)
-		character = characters[characterIndex]
+		char = characters[characterIndex]
 	}
 
 	if inpututil.IsKeyJustPressed(ebiten.KeyLeft) {
-		character.NextAnimation(-1)
+		char.NextAnimation(-1)
 	}
 	if inpututil.IsKeyJustPressed(ebiten.KeyRight) {
-		character.NextAnimation(1)
+		char.NextAnimation(1)
 	}
 
 	if inpututil.IsKeyJustPressed(ebiten.KeyW) {
-		character.NextSkin(-1)
+		char.NextSkin(-1)
 	}
 	if inpututil.IsKeyJustPressed(ebiten.KeyS) {
-		character.NextSkin(1)
+		char.NextSkin(1)
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyP) {
+		err := char.NextPlaylist(func(playlist animation.Playlist) {
+			log.Printf("%v: playlist %q done", char.Skeleton.Data.Name, playlist.Name)
+		})
+		if err != nil {
+			log.Printf("%v: %v", char.Skeleton.Data.Name, err)
+		}
 	}
 
-	character.Update(1/float64(ebiten.FPS), screenWidth/2, screenHeight-50)
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		char.TogglePlay()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyComma) {
+		char.Step(-1)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyPeriod) {
+		char.Step(1)
+	}
+	for i, key := range []ebiten.Key{ebiten.Key1, ebiten.Key2, ebiten.Key3, ebiten.Key4, ebiten.Key5} {
+		if inpututil.IsKeyJustPressed(key) {
+			char.Speed = character.SpeedPresets[i]
+		}
+	}
+
+	for i, key := range []ebiten.Key{ebiten.KeyF1, ebiten.KeyF2, ebiten.KeyF3, ebiten.KeyF4, ebiten.KeyF5, ebiten.KeyF6, ebiten.KeyF7, ebiten.KeyF8} {
+		if inpututil.IsKeyJustPressed(key) {
+			char.Debug ^= 1 << uint(i)
+		}
+	}
+
+	mx, my := ebiten.CursorPosition()
+	timeline.Update(char, float64(mx), float64(my), ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft))
+
+	char.Update(1/float64(ebiten.FPS), screenWidth/2, screenHeight-50)
 
 	if ebiten.IsRunningSlowly() {
 		return nil
 	}
 
-	screen.Clear()
+	if char.Background != nil {
+		screen.Fill(char.Background)
+	} else {
+		screen.Clear()
+	}
+
+	renderer.Target = screen
+	char.Draw(renderer)
+	renderer.DrawDebug(char)
 
-	character.Draw(screen)
+	ebitenutil.DrawRect(screen, timeline.X, timeline.Y, timeline.Width, timeline.Height, color.Gray{0x80})
+	ebitenutil.DrawRect(screen, timeline.Playhead(char)-1, timeline.Y-3, 3, timeline.Height+6, color.White)
 
-	ebitenutil.DebugPrint(screen, character.Description())
+	ebitenutil.DebugPrint(screen, char.Description()+"\n"+char.TransportDescription()+"\ndebug: "+char.Debug.String())
 
 	return nil
 }
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/adinfinit/spine"
+	"github.com/adinfinit/spine-examples/atlas"
+	"github.com/adinfinit/spine-examples/character"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// Renderer draws characters to an ebiten image.
+type Renderer struct {
+	// Target is the image to draw to
+	Target *ebiten.Image
+	Pages  map[*atlas.Page]*ebiten.Image
+
+	// white is a solid image for drawing debug shapes
+	white *ebiten.Image
+}
+
+func NewRenderer() *Renderer {
+	return &Renderer{
+		Pages: make(map[*atlas.Page]*ebiten.Image),
+	}
+}
+
+// LoadPage uploads an atlas page, ebiten premultiplies straight images itself.
+func (renderer *Renderer) LoadPage(page *atlas.Page, m image.Image, pma bool) error {
+	filter := ebiten.FilterNearest
+	if page.MagFilter.Smooth() {
+		filter = ebiten.FilterLinear
+	}
+
+	img, err := ebiten.NewImageFromImage(m, filter)
+	if err != nil {
+		return err
+	}
+	renderer.Pages[page] = img
+	return nil
+}
+
+// Draw draws a batch to the target.
+func (renderer *Renderer) Draw(batch *character.Batch) {
+	vertices := make([]ebiten.Vertex, len(batch.Vertices))
+	for i, v := range batch.Vertices {
+		vertices[i] = ebiten.Vertex{
+			DstX: v.X, DstY: v.Y,
+			SrcX: v.U, SrcY: v.V,
+			ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1,
+		}
+	}
+
+	// tint the texture
+	var tint ebiten.ColorM
+	tint.Scale(batch.Color.NRGBA64())
+
+	var draw ebiten.DrawTrianglesOptions
+	for _, pass := range BlendPasses(batch.Blend, tint) {
+		draw.ColorM = pass.ColorM
+		draw.CompositeMode = pass.CompositeMode
+		splitTriangles(vertices, batch.Indices, func(vertices []ebiten.Vertex, indices []uint16) {
+			renderer.Target.DrawTriangles(vertices, indices, renderer.Pages[batch.Page], &draw)
+		})
+	}
+}
+
+// maxVertices is the number of vertices addressable by uint16 indices.
+const maxVertices = math.MaxUint16
+
+// splitTriangles calls draw with chunks of the triangles,
+// which use at most maxVertices vertices.
+func splitTriangles(vertices []ebiten.Vertex, indices []int, draw func([]ebiten.Vertex, []uint16)) {
+	if len(vertices) <= maxVertices {
+		chunk := make([]uint16, len(indices))
+		for i, index := range indices {
+			chunk[i] = uint16(index)
+		}
+		draw(vertices, chunk)
+		return
+	}
+
+	var chunkVertices []ebiten.Vertex
+	var chunkIndices []uint16
+	remap := map[int]uint16{}
+	for i := 0; i+2 < len(indices); i += 3 {
+		if len(chunkVertices)+3 > maxVertices {
+			draw(chunkVertices, chunkIndices)
+			chunkVertices, chunkIndices = nil, nil
+			remap = map[int]uint16{}
+		}
+		for _, index := range indices[i : i+3] {
+			mapped, ok := remap[index]
+			if !ok {
+				mapped = uint16(len(chunkVertices))
+				remap[index] = mapped
+				chunkVertices = append(chunkVertices, vertices[index])
+			}
+			chunkIndices = append(chunkIndices, mapped)
+		}
+	}
+	if len(chunkIndices) > 0 {
+		draw(chunkVertices, chunkIndices)
+	}
+}
+
+// DrawDebug draws the enabled debug overlays of char to the target.
+func (renderer *Renderer) DrawDebug(char *character.Character) {
+	if char.Debug == character.DebugNone {
+		return
+	}
+
+	if renderer.white == nil {
+		white, err := ebiten.NewImage(1, 1, ebiten.FilterNearest)
+		if err != nil {
+			return
+		}
+		white.Fill(color.White)
+		renderer.white = white
+	}
+
+	points, triangles := character.DebugTriangles(char.DebugShapes(), 1)
+	vertices := make([]ebiten.Vertex, len(points))
+	for i, p := range points {
+		// vertex colors multiply the premultiplied white image
+		vertices[i] = ebiten.Vertex{
+			DstX: p.X, DstY: p.Y,
+			ColorR: p.Color.R * p.Color.A,
+			ColorG: p.Color.G * p.Color.A,
+			ColorB: p.Color.B * p.Color.A,
+			ColorA: p.Color.A,
+		}
+	}
+
+	splitTriangles(vertices, triangles, func(vertices []ebiten.Vertex, indices []uint16) {
+		renderer.Target.DrawTriangles(vertices, indices, renderer.white, &ebiten.DrawTrianglesOptions{})
+	})
+}
+
+// BlendPass describes a single draw of a blend mode.
+type BlendPass struct {
+	ColorM        ebiten.ColorM
+	CompositeMode ebiten.CompositeMode
+}
+
+// BlendPasses returns the draws needed to blend an image tinted with tint.
+func BlendPasses(blend spine.BlendMode, tint ebiten.ColorM) []BlendPass {
+	switch blend {
+	case spine.Additive:
+		return []BlendPass{{tint, ebiten.CompositeModeLighter}}
+	case spine.Multiply:
+		return []BlendPass{{tint, ebiten.CompositeModeMultiply}}
+	case spine.Screen:
+		// screen is src + dst * (1 - src), there's no such composite
+		// mode, so darken with the inverted source and then add the source
+		invert := tint
+		invert.Scale(-1, -1, -1, 1)
+		invert.Translate(1, 1, 1, 0)
+		return []BlendPass{
+			{invert, ebiten.CompositeModeMultiply},
+			{tint, ebiten.CompositeModeLighter},
+		}
+	default:
+		return []BlendPass{{tint, ebiten.CompositeModeSourceOver}}
+	}
+}
@@ -0,0 +1,187 @@
+// Package headless renders characters into images without a GPU.
+//
+// It implements character.Renderer using the software rasterizer,
+// so it draws the same batches as the demos.
+package headless
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/adinfinit/spine"
+	"github.com/adinfinit/spine-examples/atlas"
+	"github.com/adinfinit/spine-examples/character"
+	"github.com/adinfinit/spine-examples/raster"
+)
+
+// Renderer draws characters into an image.
+type Renderer struct {
+	// Target is the image to draw to, it's premultiplied
+	Target *image.RGBA
+	Pages  map[*atlas.Page]*raster.Texture
+}
+
+func NewRenderer() *Renderer {
+	return &Renderer{
+		Pages: make(map[*atlas.Page]*raster.Texture),
+	}
+}
+
+// LoadPage converts an atlas page to a texture.
+//
+// Textures are always stored premultiplied, because the target is.
+// With straight alpha pages this matches what GPU backends do internally.
+func (renderer *Renderer) LoadPage(page *atlas.Page, m image.Image, pma bool) error {
+	tex := raster.NewTexture(m, true)
+	tex.Smooth = page.MagFilter.Smooth()
+	renderer.Pages[page] = tex
+	return nil
+}
+
+// Draw draws a batch to the target.
+func (renderer *Renderer) Draw(batch *character.Batch) {
+	tex := renderer.Pages[batch.Page]
+	width, height := tex.Size()
+
+	r, g, b, a := batch.Color.RGBA64()
+	tint := raster.Color{R: float32(r), G: float32(g), B: float32(b), A: float32(a)}
+
+	vertices := make([]raster.Vertex, len(batch.Vertices))
+	for i, v := range batch.Vertices {
+		vertices[i] = raster.Vertex{
+			X:     v.X,
+			Y:     v.Y,
+			U:     v.U / float32(width),
+			V:     v.V / float32(height),
+			Color: tint,
+		}
+	}
+
+	raster.DrawTriangles(renderer.Target, tex, vertices, batch.Indices, raster.Blend(batch.Blend, true))
+}
+
+// DrawShapes draws debug shapes to the target, lines are width pixels wide.
+func (renderer *Renderer) DrawShapes(shapes []character.Shape, width float32) {
+	debug, indices := character.DebugTriangles(shapes, width)
+
+	vertices := make([]raster.Vertex, len(debug))
+	for i, v := range debug {
+		r, g, b, a := v.Color.RGBA64()
+		vertices[i] = raster.Vertex{
+			X:     v.X,
+			Y:     v.Y,
+			Color: raster.Color{R: float32(r), G: float32(g), B: float32(b), A: float32(a)},
+		}
+	}
+
+	raster.DrawTriangles(renderer.Target, nil, vertices, indices, raster.Blend(spine.Normal, true))
+}
+
+// Render draws the current pose of char into a new image,
+// together with the debug overlays selected by char.Debug.
+//
+// The skeleton is drawn in world coordinates, use FlipY
+// and the skeleton position to place it inside bounds.
+// Background may be nil for a transparent image.
+func (renderer *Renderer) Render(char *character.Character, bounds image.Rectangle, background color.Color) *image.RGBA {
+	m := image.NewRGBA(bounds)
+	if background != nil {
+		draw.Draw(m, bounds, image.NewUniform(background), image.Point{}, draw.Src)
+	}
+
+	renderer.Target = m
+	char.Draw(renderer)
+	if char.Debug != character.DebugNone {
+		renderer.DrawShapes(char.DebugShapes(), 1)
+	}
+	renderer.Target = nil
+
+	return m
+}
+
+// Viewport places a skeleton inside an image.
+type Viewport struct {
+	// Scale is the skeleton scale
+	Scale float64
+	// X, Y is the skeleton position
+	X, Y float64
+	// Bounds is the image size
+	Bounds image.Rectangle
+}
+
+// Fit returns a viewport that contains world bounds min, max, which
+// have been measured with scale 1 at the origin, scaled by scale
+// and surrounded by padding pixels.
+func Fit(min, max spine.Vector, scale float64, padding int) Viewport {
+	width := int(math.Ceil(float64(max.X-min.X)*scale)) + 2*padding
+	height := int(math.Ceil(float64(max.Y-min.Y)*scale)) + 2*padding
+	return Viewport{
+		Scale:  scale,
+		X:      float64(padding) - float64(min.X)*scale,
+		Y:      float64(padding) - float64(min.Y)*scale,
+		Bounds: image.Rect(0, 0, width, height),
+	}
+}
+
+// Measure returns the world bounds of the current animation of char
+// at the specified times, measured with scale 1 at the origin.
+func Measure(char *character.Character, times []float64) (min, max spine.Vector) {
+	first := true
+	for _, t := range times {
+		Pose(char, t, Viewport{Scale: 1})
+
+		batches := char.Batches()
+		if len(batches) == 0 {
+			continue
+		}
+		lo, hi := character.Bounds(batches)
+		if first {
+			min, max = lo, hi
+			first = false
+			continue
+		}
+		min.X, min.Y = minf(min.X, lo.X), minf(min.Y, lo.Y)
+		max.X, max.Y = maxf(max.X, hi.X), maxf(max.Y, hi.Y)
+	}
+	return min, max
+}
+
+// SampleTimes returns the frame times for a looping animation.
+//
+// The frames are spread evenly over the duration, the last frame
+// is one step before the end, which would repeat the first frame.
+func SampleTimes(duration, fps float64) []float64 {
+	count := int(math.Round(duration * fps))
+	if count < 1 {
+		return []float64{0}
+	}
+
+	times := make([]float64, count)
+	for i := range times {
+		times[i] = duration * float64(i) / float64(count)
+	}
+	return times
+}
+
+// Pose applies the current animation of char at time t and places it in viewport.
+func Pose(char *character.Character, t float64, viewport Viewport) {
+	char.Seek(t)
+	char.Scale = viewport.Scale
+	char.Update(0, viewport.X, viewport.Y)
+}
+
+func minf(a, b float32) float32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxf(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
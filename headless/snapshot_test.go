@@ -0,0 +1,241 @@
+package headless
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adinfinit/spine-examples/animation"
+	"github.com/adinfinit/spine-examples/character"
+)
+
+var update = flag.Bool("update", false, "regenerate the reference images in testdata")
+
+const (
+	// snapshotFrames is the number of frames rendered per animation
+	snapshotFrames = 3
+	// snapshotSize is the size of the longer image side
+	snapshotSize    = 256
+	snapshotPadding = 4
+	// snapshotTolerance is the largest allowed difference of a channel (0-255)
+	snapshotTolerance = 2
+
+	goldenDir = "testdata"
+	// failures are written next to the references, ignored by git
+	failedDir = "testdata/failed"
+)
+
+// snapshotBackground is drawn below every frame, so that blending is visible
+var snapshotBackground = color.RGBA{0x66, 0x99, 0xcc, 0xff}
+
+var sanitize = strings.NewReplacer("/", "_", "\\", "_", ":", "_", " ", "_")
+
+// TestSnapshots renders frames of every location, skin and animation
+// and compares them to the reference images in testdata.
+//
+// After an intended rendering change regenerate the references with
+//
+//	go test ./headless -run Snapshots -update
+func TestSnapshots(t *testing.T) {
+	locations, err := animation.LoadList(animation.Assets("../animation"))
+	if err != nil {
+		t.Log(err)
+	}
+	if len(locations) == 0 {
+		t.Fatal("no locations")
+	}
+
+	if !*update {
+		os.RemoveAll(failedDir)
+	}
+
+	renderer := NewRenderer()
+	for _, loc := range locations {
+		loc := loc
+		t.Run(sanitize.Replace(loc.Name), func(t *testing.T) {
+			char, err := character.LoadCharacter(loc, renderer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// ensure that images have y pointing down
+			char.Skeleton.FlipY = true
+
+			for skinIndex, skin := range char.Skeleton.Data.Skins {
+				char.SetSkin(skinIndex)
+				for animationIndex, anim := range char.Skeleton.Data.Animations {
+					char.SetAnimation(animationIndex)
+
+					times := []float64{0}
+					for i := 1; i < snapshotFrames && anim.Duration > 0; i++ {
+						times = append(times, float64(anim.Duration)*float64(i)/snapshotFrames)
+					}
+					viewport := snapshotViewport(char, times)
+
+					for frame, time := range times {
+						Pose(char, time, viewport)
+						m := renderer.Render(char, viewport.Bounds, snapshotBackground)
+
+						name := filepath.Join(
+							sanitize.Replace(loc.Name),
+							sanitize.Replace(skin.Name),
+							fmt.Sprintf("%v-%d.png", sanitize.Replace(anim.Name), frame))
+						checkSnapshot(t, name, m)
+					}
+				}
+			}
+		})
+	}
+}
+
+// snapshotViewport fits all poses at times into snapshotSize.
+func snapshotViewport(char *character.Character, times []float64) Viewport {
+	min, max := Measure(char, times)
+	longest := max.X - min.X
+	if h := max.Y - min.Y; h > longest {
+		longest = h
+	}
+	scale := 1.0
+	if longest > 0 {
+		scale = float64(snapshotSize-2*snapshotPadding) / float64(longest)
+	}
+	return Fit(min, max, scale, snapshotPadding)
+}
+
+// checkSnapshot compares m against the reference image name,
+// on failure the actual and the diff image are written to failedDir.
+func checkSnapshot(t *testing.T, name string, m *image.RGBA) {
+	t.Helper()
+
+	if *update {
+		if err := writePNG(filepath.Join(goldenDir, name), m); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	fail := func(format string, args ...interface{}) {
+		t.Helper()
+		base := filepath.Join(failedDir, strings.TrimSuffix(name, ".png"))
+		if err := writePNG(base+"-actual.png", m); err != nil {
+			t.Error(err)
+		}
+		t.Errorf("%v: %v", name, fmt.Sprintf(format, args...))
+	}
+
+	expected, err := readPNG(filepath.Join(goldenDir, name))
+	if os.IsNotExist(err) {
+		fail("missing reference, run with -update")
+		return
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected.Rect != m.Rect {
+		fail("size %v, expected %v", m.Rect.Size(), expected.Rect.Size())
+		return
+	}
+
+	maxdiff, count, diff := compareImages(expected, m)
+	if count > 0 {
+		base := filepath.Join(failedDir, strings.TrimSuffix(name, ".png"))
+		if err := writePNG(base+"-diff.png", diff); err != nil {
+			t.Error(err)
+		}
+		fail("%d pixels over tolerance, max difference %d", count, maxdiff)
+	}
+}
+
+// compareImages returns the largest channel difference and a diff image,
+// which highlights pixels over tolerance in red.
+func compareImages(expected, actual *image.RGBA) (maxdiff int, count int, diff *image.RGBA) {
+	diff = image.NewRGBA(expected.Rect)
+	for y := expected.Rect.Min.Y; y < expected.Rect.Max.Y; y++ {
+		for x := expected.Rect.Min.X; x < expected.Rect.Max.X; x++ {
+			a := expected.RGBAAt(x, y)
+			b := actual.RGBAAt(x, y)
+
+			d := maxint(
+				absdiff(a.R, b.R), absdiff(a.G, b.G),
+				absdiff(a.B, b.B), absdiff(a.A, b.A))
+			if d > maxdiff {
+				maxdiff = d
+			}
+
+			if d > snapshotTolerance {
+				count++
+				diff.SetRGBA(x, y, color.RGBA{0xff, 0, 0, 0xff})
+				continue
+			}
+
+			// faded expected image for context
+			gray := uint8((int(a.R) + int(a.G) + int(a.B)) / 3 / 4)
+			diff.SetRGBA(x, y, color.RGBA{gray, gray, gray, 0xff})
+		}
+	}
+	return maxdiff, count, diff
+}
+
+func absdiff(a, b uint8) int {
+	if a > b {
+		return int(a - b)
+	}
+	return int(b - a)
+}
+
+func maxint(values ...int) int {
+	r := values[0]
+	for _, v := range values[1:] {
+		if v > r {
+			r = v
+		}
+	}
+	return r
+}
+
+func readPNG(path string) (*image.RGBA, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	m, err := png.Decode(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if rgba, ok := m.(*image.RGBA); ok {
+		return rgba, nil
+	}
+	rgba := image.NewRGBA(m.Bounds())
+	for y := rgba.Rect.Min.Y; y < rgba.Rect.Max.Y; y++ {
+		for x := rgba.Rect.Min.X; x < rgba.Rect.Max.X; x++ {
+			rgba.Set(x, y, m.At(x, y))
+		}
+	}
+	return rgba, nil
+}
+
+func writePNG(path string, m image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := png.Encode(file, m); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
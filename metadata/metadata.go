@@ -0,0 +1,329 @@
+// Package metadata reads the parts of JSON skeleton exports
+// that github.com/adinfinit/spine doesn't expose.
+package metadata
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// Skeleton contains additional information about an export.
+type Skeleton struct {
+	// Images contains the size of every region and mesh attachment image,
+	// keyed by the attachment path (or name, when path is not specified)
+	Images map[string]Size
+	// Clippings contains clipping attachments keyed by skin, slot and attachment name
+	Clippings map[AttachmentKey]*Clipping
+	// BoundingBoxes, Paths and Meshes are keyed the same way
+	BoundingBoxes map[AttachmentKey]*Polygon
+	Paths         map[AttachmentKey]*Path
+	Meshes        map[AttachmentKey]*Mesh
+	// SetupAttachments contains the setup pose attachment name of every slot
+	SetupAttachments map[string]string
+	// IKConstraints contains the IK constraints in export order
+	IKConstraints []IKConstraint
+	// Events contains the keyed events of every animation sorted by time
+	Events map[string][]Event
+	// Attachments contains the attachment keys of every animation
+	// by slot name, sorted by time
+	Attachments map[string]map[string][]AttachmentFrame
+}
+
+// AttachmentFrame is a keyed attachment change of a slot,
+// an empty Name hides the attachment.
+type AttachmentFrame struct {
+	Time float32
+	Name string
+}
+
+// Event is a keyed event of an animation.
+//
+// Values that are not keyed are taken from the event defaults.
+type Event struct {
+	Time   float32 `json:"time"`
+	Name   string  `json:"name"`
+	Int    int     `json:"int"`
+	Float  float32 `json:"float"`
+	String string  `json:"string"`
+}
+
+// AttachmentKey identifies an attachment in a skin.
+type AttachmentKey struct {
+	Skin, Slot, Name string
+}
+
+// Polygon contains the vertices of an attachment.
+type Polygon struct {
+	// VertexCount is the number of polygon vertices
+	VertexCount int
+	// Vertices are the polygon vertices in the export format:
+	// x, y pairs relative to the slot bone when not weighted, otherwise
+	// for every vertex the bone count followed by bone, x, y, weight
+	Vertices []float32
+}
+
+// Weighted returns whether the vertices are bound to multiple bones.
+func (polygon *Polygon) Weighted() bool {
+	return len(polygon.Vertices) != polygon.VertexCount*2
+}
+
+// Clipping is a clipping attachment.
+type Clipping struct {
+	// End is the name of the slot after which clipping stops
+	End string
+	Polygon
+}
+
+// Path is a path attachment, its vertices are the control points
+// of cubic bezier curves: in handle, point, out handle for every point.
+type Path struct {
+	Closed bool
+	Polygon
+}
+
+// Mesh contains the parts of a mesh attachment used for debugging.
+type Mesh struct {
+	// Hull is the number of vertices on the hull, they come first
+	Hull int
+}
+
+// IKConstraint is an IK constraint.
+type IKConstraint struct {
+	Name string `json:"name"`
+	// Bones are the names of the constrained bones, parent first
+	Bones  []string `json:"bones"`
+	Target string   `json:"target"`
+}
+
+// FindClipping finds a clipping attachment from skin or the default skin.
+func (skeleton *Skeleton) FindClipping(skin, slot, name string) *Clipping {
+	if clipping, ok := skeleton.Clippings[AttachmentKey{skin, slot, name}]; ok {
+		return clipping
+	}
+	return skeleton.Clippings[AttachmentKey{"default", slot, name}]
+}
+
+// FindBoundingBox finds a bounding box attachment from skin or the default skin.
+func (skeleton *Skeleton) FindBoundingBox(skin, slot, name string) *Polygon {
+	if box, ok := skeleton.BoundingBoxes[AttachmentKey{skin, slot, name}]; ok {
+		return box
+	}
+	return skeleton.BoundingBoxes[AttachmentKey{"default", slot, name}]
+}
+
+// FindPath finds a path attachment from skin or the default skin.
+func (skeleton *Skeleton) FindPath(skin, slot, name string) *Path {
+	if path, ok := skeleton.Paths[AttachmentKey{skin, slot, name}]; ok {
+		return path
+	}
+	return skeleton.Paths[AttachmentKey{"default", slot, name}]
+}
+
+// FindMesh finds a mesh or linked mesh attachment from skin or the default skin.
+func (skeleton *Skeleton) FindMesh(skin, slot, name string) *Mesh {
+	if mesh, ok := skeleton.Meshes[AttachmentKey{skin, slot, name}]; ok {
+		return mesh
+	}
+	return skeleton.Meshes[AttachmentKey{"default", slot, name}]
+}
+
+// Size is the size of an attachment image in skeleton units.
+type Size struct {
+	Width, Height float32
+}
+
+type jsonAttachment struct {
+	Name   string  `json:"name"`
+	Path   string  `json:"path"`
+	Type   string  `json:"type"`
+	Width  float32 `json:"width"`
+	Height float32 `json:"height"`
+
+	End         string    `json:"end"`
+	VertexCount int       `json:"vertexCount"`
+	Vertices    []float32 `json:"vertices"`
+
+	Closed bool `json:"closed"`
+	Hull   int  `json:"hull"`
+	// Parent and Skin refer to the parent of a linked mesh
+	Parent string `json:"parent"`
+	Skin   string `json:"skin"`
+}
+
+type jsonEvent struct {
+	Time   float32  `json:"time"`
+	Name   string   `json:"name"`
+	Int    *int     `json:"int"`
+	Float  *float32 `json:"float"`
+	String *string  `json:"string"`
+}
+
+type jsonSkeleton struct {
+	Slots []struct {
+		Name       string `json:"name"`
+		Attachment string `json:"attachment"`
+	} `json:"slots"`
+	IK         []IKConstraint                                  `json:"ik"`
+	Skins      map[string]map[string]map[string]jsonAttachment `json:"skins"`
+	Events     map[string]jsonEvent                            `json:"events"`
+	Animations map[string]struct {
+		Events []jsonEvent `json:"events"`
+		Slots  map[string]struct {
+			Attachment []struct {
+				Time float32 `json:"time"`
+				Name string  `json:"name"`
+			} `json:"attachment"`
+		} `json:"slots"`
+	} `json:"animations"`
+}
+
+// Read reads metadata from a JSON export.
+func Read(rd io.Reader) (*Skeleton, error) {
+	var data jsonSkeleton
+	if err := json.NewDecoder(rd).Decode(&data); err != nil {
+		return nil, err
+	}
+
+	skeleton := &Skeleton{
+		Images:    map[string]Size{},
+		Clippings: map[AttachmentKey]*Clipping{},
+		Events:    map[string][]Event{},
+
+		Attachments: map[string]map[string][]AttachmentFrame{},
+
+		BoundingBoxes:    map[AttachmentKey]*Polygon{},
+		Paths:            map[AttachmentKey]*Path{},
+		Meshes:           map[AttachmentKey]*Mesh{},
+		SetupAttachments: map[string]string{},
+		IKConstraints:    data.IK,
+	}
+
+	for _, slot := range data.Slots {
+		if slot.Attachment != "" {
+			skeleton.SetupAttachments[slot.Name] = slot.Attachment
+		}
+	}
+
+	var linked []AttachmentKey
+
+	for skin, slots := range data.Skins {
+		for slot, attachments := range slots {
+			for key, attachment := range attachments {
+				polygon := Polygon{
+					VertexCount: attachment.VertexCount,
+					Vertices:    attachment.Vertices,
+				}
+				switch attachment.Type {
+				case "clipping":
+					skeleton.Clippings[AttachmentKey{skin, slot, key}] = &Clipping{
+						End:     attachment.End,
+						Polygon: polygon,
+					}
+					continue
+				case "boundingbox":
+					skeleton.BoundingBoxes[AttachmentKey{skin, slot, key}] = &polygon
+					continue
+				case "path":
+					skeleton.Paths[AttachmentKey{skin, slot, key}] = &Path{
+						Closed:  attachment.Closed,
+						Polygon: polygon,
+					}
+					continue
+				case "mesh":
+					skeleton.Meshes[AttachmentKey{skin, slot, key}] = &Mesh{Hull: attachment.Hull}
+				case "linkedmesh":
+					parentSkin := attachment.Skin
+					if parentSkin == "" {
+						parentSkin = "default"
+					}
+					linked = append(linked, AttachmentKey{skin, slot, key}, AttachmentKey{parentSkin, slot, attachment.Parent})
+				case "", "region":
+				default:
+					continue
+				}
+				if attachment.Width == 0 || attachment.Height == 0 {
+					continue
+				}
+
+				path := attachment.Path
+				if path == "" {
+					path = attachment.Name
+				}
+				if path == "" {
+					path = key
+				}
+				skeleton.Images[path] = Size{attachment.Width, attachment.Height}
+			}
+		}
+	}
+
+	// linked meshes share the hull of their parent
+	for i := 0; i+1 < len(linked); i += 2 {
+		if parent, ok := skeleton.Meshes[linked[i+1]]; ok {
+			skeleton.Meshes[linked[i]] = parent
+		}
+	}
+
+	for name, animation := range data.Animations {
+		events := make([]Event, 0, len(animation.Events))
+		for _, keyed := range animation.Events {
+			event := Event{Time: keyed.Time, Name: keyed.Name}
+			defaults := data.Events[keyed.Name]
+			event.Int = intOr(keyed.Int, defaults.Int)
+			event.Float = floatOr(keyed.Float, defaults.Float)
+			event.String = stringOr(keyed.String, defaults.String)
+			events = append(events, event)
+		}
+		sort.SliceStable(events, func(i, k int) bool {
+			return events[i].Time < events[k].Time
+		})
+		skeleton.Events[name] = events
+
+		for slot, timelines := range animation.Slots {
+			if len(timelines.Attachment) == 0 {
+				continue
+			}
+			frames := make([]AttachmentFrame, 0, len(timelines.Attachment))
+			for _, keyed := range timelines.Attachment {
+				frames = append(frames, AttachmentFrame{Time: keyed.Time, Name: keyed.Name})
+			}
+			sort.SliceStable(frames, func(i, k int) bool {
+				return frames[i].Time < frames[k].Time
+			})
+			if skeleton.Attachments[name] == nil {
+				skeleton.Attachments[name] = map[string][]AttachmentFrame{}
+			}
+			skeleton.Attachments[name][slot] = frames
+		}
+	}
+
+	return skeleton, nil
+}
+
+func intOr(v, def *int) int {
+	if v != nil {
+		return *v
+	} else if def != nil {
+		return *def
+	}
+	return 0
+}
+
+func floatOr(v, def *float32) float32 {
+	if v != nil {
+		return *v
+	} else if def != nil {
+		return *def
+	}
+	return 0
+}
+
+func stringOr(v, def *string) string {
+	if v != nil {
+		return *v
+	} else if def != nil {
+		return *def
+	}
+	return ""
+}
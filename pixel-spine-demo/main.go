@@ -1,13 +1,16 @@
 package main
 
 import (
+	"flag"
 	"log"
 	"time"
 
 	"github.com/adinfinit/spine-examples/animation"
+	"github.com/adinfinit/spine-examples/character"
 	"github.com/golang/freetype/truetype"
 
 	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
 	"github.com/faiface/pixel/pixelgl"
 	"github.com/faiface/pixel/text"
 
@@ -16,9 +19,19 @@ import (
 	"golang.org/x/image/font/gofont/goregular"
 )
 
+var (
+	assetsDir = flag.String("assets", "../animation", "animation directory")
+	binary    = flag.Bool("binary", false, "load binary .skel exports when available")
+	pma       = flag.Bool("pma", false, "use premultiplied alpha atlases when available")
+	mix       = flag.Float64("mix", -1, "default crossfade duration in seconds, negative uses the manifest")
+	events    = flag.Bool("events", false, "print animation events")
+)
+
 func main() {
+	flag.Parse()
 	pixelgl.Run(run)
 }
+
 func run() {
 	cfg := pixelgl.WindowConfig{
 		Title:  "Pixel and Spine",
@@ -40,21 +53,46 @@ func run() {
 	txt := text.New(pixel.V(0, 0), atlas)
 	txt.Color = colornames.Black
 
-	var characters []*Character
-	for _, loc := range animation.LoadList("../animation") {
-		character, err := LoadCharacter(loc)
+	var characters []*character.Character
+	locations, err := animation.LoadList(animation.Assets(*assetsDir))
+	if err != nil {
+		log.Println(err)
+	}
+	if *binary {
+		animation.PreferFormat(locations, animation.FormatBinary)
+	}
+	if *pma {
+		animation.EnablePMA(locations)
+	}
+
+	// characters are drawn to a canvas, because multiply and
+	// screen blending need to read back the pixels
+	canvas := pixelgl.NewCanvas(win.Bounds())
+	renderer := NewRenderer(canvas)
+
+	for _, loc := range locations {
+		char, err := character.LoadCharacter(loc, renderer)
 		if err != nil {
 			log.Println(loc.Name, err)
 			continue
 		}
+		if *mix >= 0 {
+			char.State.Mixes.Default = *mix
+		}
+		if *events {
+			char.State.AddListener(character.LogEvents(loc.Name))
+		}
 
-		characters = append(characters, character)
+		characters = append(characters, char)
 	}
 
-	var character *Character
-	character = characters[0]
+	var char *character.Character
+	char = characters[0]
 	characterIndex := 0
 
+	timeline := &character.Timeline{X: 10, Y: 10, Width: win.Bounds().W() - 20, Height: 10}
+	bar := imdraw.New(nil)
+
 	last := time.Now()
 	for !win.Closed() {
 		dt := time.Since(last).Seconds()
@@ -62,36 +100,88 @@ func run() {
 
 		if win.JustPressed(pixelgl.KeyUp) {
 			characterIndex = (characterIndex + len(characters) - 1) % len(characters)
-			character = characters[characterIndex]
+			char = characters[characterIndex]
 		}
 		if win.JustPressed(pixelgl.KeyDown) {
 			characterIndex = (characterIndex + len(characters) + 1) % len(characters)
-			character = characters[characterIndex]
+			char = characters[characterIndex]
 		}
 
 		if win.JustPressed(pixelgl.KeyLeft) {
-			character.NextAnimation(-1)
+			char.NextAnimation(-1)
 		}
 		if win.JustPressed(pixelgl.KeyRight) {
-			character.NextAnimation(1)
+			char.NextAnimation(1)
 		}
 
 		if win.JustPressed(pixelgl.KeyW) {
-			character.NextSkin(-1)
+			char.NextSkin(-1)
 		}
 		if win.JustPressed(pixelgl.KeyS) {
-			character.NextSkin(1)
+			char.NextSkin(1)
+		}
+		if win.JustPressed(pixelgl.KeyP) {
+			err := char.NextPlaylist(func(playlist animation.Playlist) {
+				log.Printf("%v: playlist %q done", char.Skeleton.Data.Name, playlist.Name)
+			})
+			if err != nil {
+				log.Printf("%v: %v", char.Skeleton.Data.Name, err)
+			}
+		}
+
+		if win.JustPressed(pixelgl.KeySpace) {
+			char.TogglePlay()
+		}
+		if win.JustPressed(pixelgl.KeyComma) {
+			char.Step(-1)
+		}
+		if win.JustPressed(pixelgl.KeyPeriod) {
+			char.Step(1)
+		}
+		for i, key := range []pixelgl.Button{pixelgl.Key1, pixelgl.Key2, pixelgl.Key3, pixelgl.Key4, pixelgl.Key5} {
+			if win.JustPressed(key) {
+				char.Speed = character.SpeedPresets[i]
+			}
+		}
+
+		for i, key := range []pixelgl.Button{pixelgl.KeyF1, pixelgl.KeyF2, pixelgl.KeyF3, pixelgl.KeyF4, pixelgl.KeyF5, pixelgl.KeyF6, pixelgl.KeyF7, pixelgl.KeyF8} {
+			if win.JustPressed(key) {
+				char.Debug ^= 1 << uint(i)
+			}
+		}
+
+		mouse := win.MousePosition()
+		timeline.Update(char, mouse.X, mouse.Y, win.Pressed(pixelgl.MouseButtonLeft))
+
+		if char.Background != nil {
+			canvas.Clear(char.Background)
+		} else {
+			canvas.Clear(colornames.Lightgray)
 		}
-		win.Clear(colornames.Lightgray)
 
-		center := win.Bounds().Center()
+		center := canvas.Bounds().Center()
 		center.Y = 50
-		character.Update(dt, center)
-		character.Draw(win)
+		char.Update(dt, center.X, center.Y)
+		char.Draw(renderer)
+		renderer.Flush()
+		DrawDebug(char, canvas)
+
+		win.Clear(colornames.Black)
+		canvas.Draw(win, pixel.IM.Moved(win.Bounds().Center()))
+
+		bar.Clear()
+		bar.Color = colornames.Gray
+		bar.Push(pixel.V(timeline.X, timeline.Y), pixel.V(timeline.X+timeline.Width, timeline.Y+timeline.Height))
+		bar.Rectangle(0)
+		bar.Color = colornames.White
+		playhead := timeline.Playhead(char)
+		bar.Push(pixel.V(playhead-1, timeline.Y-3), pixel.V(playhead+2, timeline.Y+timeline.Height+3))
+		bar.Rectangle(0)
+		bar.Draw(win)
 
 		txt.Clear()
-		txt.WriteString(character.Description())
-		txt.Draw(win, pixel.IM.Moved(pixel.Vec{50, 50}))
+		txt.WriteString(char.Description() + "\n" + char.TransportDescription() + "\ndebug: " + char.Debug.String())
+		txt.Draw(win, pixel.IM.Moved(pixel.Vec{50, 100}))
 
 		win.Update()
 	}
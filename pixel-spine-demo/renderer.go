@@ -0,0 +1,165 @@
+package main
+
+import (
+	"image"
+
+	"github.com/adinfinit/spine"
+	"github.com/adinfinit/spine-examples/atlas"
+	"github.com/adinfinit/spine-examples/character"
+	"github.com/adinfinit/spine-examples/raster"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/pixelgl"
+)
+
+// Renderer draws characters to a canvas.
+//
+// Pixel only supports Porter-Duff composition, which cannot express
+// multiply and screen blending. Those batches are composited in software
+// on the canvas pixels, call Flush after drawing to upload them.
+type Renderer struct {
+	Canvas *pixelgl.Canvas
+	// Pages are the atlas pages, the canvas smoothing is switched
+	// to the filter of the page before drawing each batch
+	Pages map[*atlas.Page]*pixel.PictureData
+	// Textures are the pages for software blending
+	Textures map[*atlas.Page]*raster.Texture
+
+	// software contains the canvas pixels while software blending
+	software *image.RGBA
+}
+
+func NewRenderer(canvas *pixelgl.Canvas) *Renderer {
+	return &Renderer{
+		Canvas:   canvas,
+		Pages:    make(map[*atlas.Page]*pixel.PictureData),
+		Textures: make(map[*atlas.Page]*raster.Texture),
+	}
+}
+
+// LoadPage converts an atlas page to picture data, which is always premultiplied.
+func (renderer *Renderer) LoadPage(page *atlas.Page, m image.Image, pma bool) error {
+	renderer.Pages[page] = pixel.PictureDataFromImage(m)
+	return nil
+}
+
+// Draw draws a batch to the canvas.
+func (renderer *Renderer) Draw(batch *character.Batch) {
+	switch batch.Blend {
+	case spine.Multiply, spine.Screen:
+		if renderer.software == nil {
+			renderer.software = CanvasImage(renderer.Canvas)
+		}
+		renderer.DrawSoftware(batch)
+		return
+	case spine.Additive:
+		renderer.Flush()
+		renderer.Canvas.SetComposeMethod(pixel.ComposePlus)
+	default:
+		renderer.Flush()
+	}
+
+	renderer.Canvas.SetSmooth(batch.Page.MagFilter.Smooth())
+	pd := renderer.Pages[batch.Page]
+	pixel.NewBatch(Triangles(batch, pd), pd).Draw(renderer.Canvas)
+}
+
+// Triangles converts batch to triangles textured with pd.
+func Triangles(batch *character.Batch, pd *pixel.PictureData) *pixel.TrianglesData {
+	height := pd.Rect.H()
+
+	var col pixel.RGBA
+	col.R, col.G, col.B, col.A = batch.Color.RGBA64()
+
+	tridata := pixel.MakeTrianglesData(len(batch.Indices))
+	for i, index := range batch.Indices {
+		v := batch.Vertices[index]
+		tri := &(*tridata)[i]
+		tri.Position = pixel.V(float64(v.X), float64(v.Y))
+		tri.Picture = pixel.V(float64(v.U), height-float64(v.V))
+		tri.Color = col
+		tri.Intensity = 1
+	}
+	return tridata
+}
+
+// Flush uploads software blended pixels and restores composition.
+func (renderer *Renderer) Flush() {
+	if renderer.software != nil {
+		renderer.Canvas.SetPixels(renderer.software.Pix)
+		renderer.software = nil
+	}
+	renderer.Canvas.SetComposeMethod(pixel.ComposeOver)
+}
+
+// CanvasImage reads the canvas pixels, rows are ordered bottom up,
+// which means that image coordinates match canvas coordinates.
+func CanvasImage(canvas *pixelgl.Canvas) *image.RGBA {
+	bounds := canvas.Bounds()
+	w, h := int(bounds.W()), int(bounds.H())
+	return &image.RGBA{
+		Pix:    canvas.Pixels(),
+		Stride: w * 4,
+		Rect:   image.Rect(0, 0, w, h),
+	}
+}
+
+// DrawSoftware blends batch to the canvas pixels.
+func (renderer *Renderer) DrawSoftware(batch *character.Batch) {
+	tex, ok := renderer.Textures[batch.Page]
+	if !ok {
+		// picture data is always premultiplied
+		tex = raster.NewTexture(renderer.Pages[batch.Page].Image(), true)
+		tex.Smooth = batch.Page.MagFilter.Smooth()
+		renderer.Textures[batch.Page] = tex
+	}
+
+	r, g, b, a := batch.Color.RGBA64()
+	tint := raster.Color{R: float32(r), G: float32(g), B: float32(b), A: float32(a)}
+
+	offset := renderer.Canvas.Bounds().Min
+	width, height := tex.Size()
+	vertices := make([]raster.Vertex, len(batch.Vertices))
+	for i, v := range batch.Vertices {
+		vertices[i] = raster.Vertex{
+			X:     v.X - float32(offset.X),
+			Y:     v.Y - float32(offset.Y),
+			U:     v.U / float32(width),
+			V:     v.V / float32(height),
+			Color: tint,
+		}
+	}
+
+	raster.DrawTriangles(renderer.software, tex, vertices, batch.Indices, raster.Blend(batch.Blend, true))
+}
+
+// DrawDebug draws the enabled debug overlays of char.
+func DrawDebug(char *character.Character, target pixel.Target) {
+	if char.Debug == character.DebugNone {
+		return
+	}
+
+	imd := imdraw.New(nil)
+	defer imd.Draw(target)
+
+	for _, shape := range char.DebugShapes() {
+		if len(shape.Points) == 0 {
+			continue
+		}
+
+		imd.Color = shape.Color.WithAlpha(shape.Color.A)
+		for _, p := range shape.Points {
+			imd.Push(pixel.V(float64(p.X), float64(p.Y)))
+		}
+		if shape.Filled {
+			imd.Polygon(0)
+			continue
+		}
+		if shape.Closed {
+			first := shape.Points[0]
+			imd.Push(pixel.V(float64(first.X), float64(first.Y)))
+		}
+		imd.Line(1)
+	}
+}
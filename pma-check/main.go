@@ -0,0 +1,208 @@
+// pma-check renders every atlas region with the straight and the
+// premultiplied alpha atlas and verifies that the results match.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/adinfinit/spine"
+	"github.com/adinfinit/spine-examples/animation"
+	"github.com/adinfinit/spine-examples/atlas"
+	"github.com/adinfinit/spine-examples/raster"
+)
+
+var (
+	assetsDir = flag.String("assets", "../animation", "animation directory")
+	tolerance = flag.Int("tolerance", 3, "maximum allowed difference of a channel (0-255)")
+	verbose   = flag.Bool("v", false, "print every region")
+)
+
+var blendModes = []struct {
+	Name string
+	Mode spine.BlendMode
+}{
+	{"normal", spine.Normal},
+	{"additive", spine.Additive},
+	{"multiply", spine.Multiply},
+	{"screen", spine.Screen},
+}
+
+var tints = []raster.Color{
+	{1, 1, 1, 1},
+	{1, 0.5, 0.25, 0.6},
+}
+
+// background is drawn below every region, it's opaque like a window
+var background = color.RGBA{0x66, 0x99, 0xcc, 0xff}
+
+// Textures contains the loaded atlas and the page textures.
+type Textures struct {
+	Atlas *atlas.Atlas
+	Pages map[*atlas.Page]*raster.Texture
+}
+
+func LoadTextures(loc animation.Location, pma bool) (*Textures, error) {
+	loc.UsePMA(pma)
+
+	result, err := loc.LoadAtlas()
+	if err != nil {
+		return nil, err
+	}
+
+	textures := &Textures{
+		Atlas: result,
+		Pages: map[*atlas.Page]*raster.Texture{},
+	}
+	for _, page := range result.Pages {
+		m, err := loc.LoadPage(page)
+		if err != nil {
+			return nil, err
+		}
+		tex := raster.NewTexture(m, pma)
+		tex.Smooth = page.MagFilter.Smooth()
+		textures.Pages[page] = tex
+	}
+
+	return textures, nil
+}
+
+// RenderRegion draws region with its original size over the background.
+func RenderRegion(tex *raster.Texture, region *atlas.Region, tint raster.Color, blend raster.BlendFunc) *image.RGBA {
+	m := image.NewRGBA(image.Rect(0, 0, region.OrigWidth, region.OrigHeight))
+	draw.Draw(m, m.Rect, image.NewUniform(background), image.Point{}, draw.Src)
+
+	left := float32(region.OffsetX)
+	top := float32(region.OrigHeight - region.OffsetY - region.Height)
+	right := left + float32(region.Width)
+	bottom := top + float32(region.Height)
+
+	corner := func(x, y float32) raster.Vertex {
+		px, py := region.PagePoint(float64(x), float64(y))
+		return raster.Vertex{
+			X: x, Y: y,
+			U:     float32(px / float64(region.Page.Width)),
+			V:     float32(py / float64(region.Page.Height)),
+			Color: tint,
+		}
+	}
+
+	vertices := []raster.Vertex{
+		corner(left, top),
+		corner(right, top),
+		corner(right, bottom),
+		corner(left, bottom),
+	}
+	raster.DrawTriangles(m, tex, vertices, []int{0, 1, 2, 0, 2, 3}, blend)
+
+	return m
+}
+
+// Compare returns the largest color difference and the number of differing pixels.
+//
+// Alpha is ignored, because with straight alpha the destination alpha
+// is blended with SrcAlpha as well, which is invisible on an opaque target.
+func Compare(a, b *image.RGBA) (maxdiff int, count int) {
+	for i := 0; i < len(a.Pix); i += 4 {
+		differs := false
+		for k := 0; k < 3; k++ {
+			d := int(a.Pix[i+k]) - int(b.Pix[i+k])
+			if d < 0 {
+				d = -d
+			}
+			if d > maxdiff {
+				maxdiff = d
+			}
+			if d > *tolerance {
+				differs = true
+			}
+		}
+		if differs {
+			count++
+		}
+	}
+	return maxdiff, count
+}
+
+func main() {
+	flag.Parse()
+
+	log.SetOutput(os.Stderr)
+	locations, err := animation.LoadList(animation.Assets(*assetsDir))
+	if err != nil {
+		log.Println(err)
+	}
+
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 4, 8, 2, ' ', 0)
+	defer w.Flush()
+
+	failed := 0
+	fmt.Fprint(w, "location\tregions\tmax diff\tpixels over\tresult\n")
+	for _, loc := range locations {
+		if loc.PMAAtlas == "" {
+			fmt.Fprintf(w, "%v\t\t\t\tno pma atlas\n", loc.Name)
+			continue
+		}
+
+		straight, err := LoadTextures(loc, false)
+		if err != nil {
+			log.Println(loc.Name, err)
+			failed++
+			continue
+		}
+		pma, err := LoadTextures(loc, true)
+		if err != nil {
+			log.Println(loc.Name, err)
+			failed++
+			continue
+		}
+
+		regions, maxdiff, over := 0, 0, 0
+		for _, region := range straight.Atlas.Regions {
+			other := pma.Atlas.Find(region.Name)
+			if other == nil {
+				log.Println(loc.Name, region.Name, "missing from pma atlas")
+				over++
+				continue
+			}
+			regions++
+
+			for _, blend := range blendModes {
+				for _, tint := range tints {
+					a := RenderRegion(straight.Pages[region.Page], region, tint, raster.Blend(blend.Mode, false))
+					b := RenderRegion(pma.Pages[other.Page], other, tint.Premultiply(), raster.Blend(blend.Mode, true))
+
+					diff, count := Compare(a, b)
+					if diff > maxdiff {
+						maxdiff = diff
+					}
+					over += count
+
+					if *verbose && count > 0 {
+						fmt.Fprintf(w, "  %v\t%v\t%v\t%v\t\n", region.Name, blend.Name, diff, count)
+					}
+				}
+			}
+		}
+
+		result := "ok"
+		if over > 0 {
+			result = "FAIL"
+			failed++
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n", loc.Name, regions, maxdiff, over, result)
+	}
+
+	w.Flush()
+	if failed > 0 {
+		fmt.Printf("%d locations failed\n", failed)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/draw"
+	"io"
+)
+
+// WriteAPNG writes frames as an animated PNG that loops forever,
+// frame i is shown for delays[i] / den seconds.
+//
+// image/png can't write animations and picks the color type per image,
+// so frames are encoded here, always as 8-bit straight alpha RGBA.
+func WriteAPNG(w io.Writer, frames []*image.RGBA, delays []int, den int) error {
+	bw := bufio.NewWriter(w)
+	enc := &apngEncoder{w: bw}
+
+	bounds := frames[0].Rect
+	width, height := uint32(bounds.Dx()), uint32(bounds.Dy())
+
+	enc.write([]byte("\x89PNG\r\n\x1a\n"))
+
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], width)
+	binary.BigEndian.PutUint32(ihdr[4:], height)
+	ihdr[8] = 8 // bit depth
+	ihdr[9] = 6 // color type RGBA
+	enc.chunk("IHDR", ihdr)
+
+	actl := make([]byte, 8)
+	binary.BigEndian.PutUint32(actl[0:], uint32(len(frames)))
+	binary.BigEndian.PutUint32(actl[4:], 0) // loop forever
+	enc.chunk("acTL", actl)
+
+	sequence := uint32(0)
+	for i, frame := range frames {
+		fctl := make([]byte, 26)
+		binary.BigEndian.PutUint32(fctl[0:], sequence)
+		binary.BigEndian.PutUint32(fctl[4:], width)
+		binary.BigEndian.PutUint32(fctl[8:], height)
+		binary.BigEndian.PutUint32(fctl[12:], 0) // x offset
+		binary.BigEndian.PutUint32(fctl[16:], 0) // y offset
+		binary.BigEndian.PutUint16(fctl[20:], uint16(delays[i]))
+		binary.BigEndian.PutUint16(fctl[22:], uint16(den))
+		fctl[24] = 0 // dispose none, every frame covers the whole image
+		fctl[25] = 0 // blend source, replace the previous frame
+		enc.chunk("fcTL", fctl)
+		sequence++
+
+		data, err := compressFrame(frame)
+		if err != nil {
+			return err
+		}
+
+		// the first frame is the default image
+		if i == 0 {
+			enc.chunk("IDAT", data)
+			continue
+		}
+
+		fdat := make([]byte, 4+len(data))
+		binary.BigEndian.PutUint32(fdat, sequence)
+		copy(fdat[4:], data)
+		enc.chunk("fdAT", fdat)
+		sequence++
+	}
+
+	enc.chunk("IEND", nil)
+	if enc.err != nil {
+		return enc.err
+	}
+	return bw.Flush()
+}
+
+type apngEncoder struct {
+	w   io.Writer
+	err error
+}
+
+func (enc *apngEncoder) write(data []byte) {
+	if enc.err != nil {
+		return
+	}
+	_, enc.err = enc.w.Write(data)
+}
+
+func (enc *apngEncoder) chunk(name string, data []byte) {
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint32(header, uint32(len(data)))
+	copy(header[4:], name)
+
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:])
+	crc.Write(data)
+
+	footer := make([]byte, 4)
+	binary.BigEndian.PutUint32(footer, crc.Sum32())
+
+	enc.write(header)
+	enc.write(data)
+	enc.write(footer)
+}
+
+// compressFrame returns zlib compressed and filtered scanlines of m.
+func compressFrame(m *image.RGBA) ([]byte, error) {
+	// PNG uses straight alpha
+	nrgba := image.NewNRGBA(image.Rect(0, 0, m.Rect.Dx(), m.Rect.Dy()))
+	draw.Draw(nrgba, nrgba.Rect, m, m.Rect.Min, draw.Src)
+
+	var buf bytes.Buffer
+	zw, err := zlib.NewWriterLevel(&buf, zlib.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+
+	rowSize := nrgba.Rect.Dx() * 4
+	prev := make([]byte, rowSize)
+	candidates := make([][]byte, 5)
+	for i := range candidates {
+		candidates[i] = make([]byte, 1+rowSize)
+	}
+
+	for y := 0; y < nrgba.Rect.Dy(); y++ {
+		row := nrgba.Pix[y*nrgba.Stride : y*nrgba.Stride+rowSize]
+		best := filterRow(candidates, row, prev)
+		if _, err := zw.Write(best); err != nil {
+			return nil, err
+		}
+		prev = row
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// filterRow applies every PNG filter to row and returns the one with
+// the smallest sum of absolute values, as suggested by the specification.
+func filterRow(candidates [][]byte, row, prev []byte) []byte {
+	const bpp = 4
+
+	best, bestSum := candidates[0], -1
+	for filter, out := range candidates {
+		out[0] = byte(filter)
+		sum := 0
+		for i, x := range row {
+			var a, b, c byte
+			if i >= bpp {
+				a, c = row[i-bpp], prev[i-bpp]
+			}
+			b = prev[i]
+
+			var v byte
+			switch filter {
+			case 0:
+				v = x
+			case 1:
+				v = x - a
+			case 2:
+				v = x - b
+			case 3:
+				v = x - byte((int(a)+int(b))/2)
+			case 4:
+				v = x - paeth(a, b, c)
+			}
+			out[1+i] = v
+
+			s := int(int8(v))
+			if s < 0 {
+				s = -s
+			}
+			sum += s
+		}
+		if bestSum < 0 || sum < bestSum {
+			best, bestSum = out, sum
+		}
+	}
+	return best
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	if pa <= pb && pa <= pc {
+		return a
+	}
+	if pb <= pc {
+		return b
+	}
+	return c
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
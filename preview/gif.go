@@ -0,0 +1,234 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"sort"
+)
+
+// alphaThreshold decides whether a pixel is transparent in a GIF
+const alphaThreshold = 0x80
+
+// WriteGIF writes frames as a GIF that loops forever, delays are in 100ths of a second.
+//
+// All frames share a palette built with median cut, GIF has no partial
+// transparency, so pixels are either opaque or fully transparent.
+func WriteGIF(w io.Writer, frames []*image.RGBA, delays []int) error {
+	palette, transparent := Quantize(frames, 256)
+
+	anim := &gif.GIF{
+		LoopCount: 0,
+		Config: image.Config{
+			ColorModel: palette,
+			Width:      frames[0].Rect.Dx(),
+			Height:     frames[0].Rect.Dy(),
+		},
+	}
+
+	lookup := map[color.RGBA]uint8{}
+	for i, frame := range frames {
+		m := image.NewPaletted(frame.Rect, palette)
+		for y := frame.Rect.Min.Y; y < frame.Rect.Max.Y; y++ {
+			for x := frame.Rect.Min.X; x < frame.Rect.Max.X; x++ {
+				c := opaque(frame.RGBAAt(x, y))
+				if c.A == 0 {
+					m.SetColorIndex(x, y, uint8(transparent))
+					continue
+				}
+
+				index, ok := lookup[c]
+				if !ok {
+					index = uint8(palette.Index(c))
+					lookup[c] = index
+				}
+				m.SetColorIndex(x, y, index)
+			}
+		}
+
+		anim.Image = append(anim.Image, m)
+		anim.Delay = append(anim.Delay, delays[i])
+		// clear the previous frame, otherwise it shows through transparent pixels
+		anim.Disposal = append(anim.Disposal, gif.DisposalBackground)
+	}
+	if transparent >= 0 {
+		anim.BackgroundIndex = uint8(transparent)
+	}
+
+	return gif.EncodeAll(w, anim)
+}
+
+// opaque converts a premultiplied color to either an opaque or a transparent color.
+func opaque(c color.RGBA) color.RGBA {
+	if c.A < alphaThreshold {
+		return color.RGBA{}
+	}
+	if c.A == 0xff {
+		return c
+	}
+	return color.RGBA{
+		R: uint8(uint32(c.R) * 0xff / uint32(c.A)),
+		G: uint8(uint32(c.G) * 0xff / uint32(c.A)),
+		B: uint8(uint32(c.B) * 0xff / uint32(c.A)),
+		A: 0xff,
+	}
+}
+
+// Quantize builds a palette of at most size colors for frames with median cut.
+//
+// When frames contain transparent pixels, the palette includes
+// a transparent color and its index is returned, otherwise -1.
+func Quantize(frames []*image.RGBA, size int) (palette color.Palette, transparent int) {
+	histogram := map[color.RGBA]int{}
+	hasTransparent := false
+	for _, frame := range frames {
+		for y := frame.Rect.Min.Y; y < frame.Rect.Max.Y; y++ {
+			for x := frame.Rect.Min.X; x < frame.Rect.Max.X; x++ {
+				c := opaque(frame.RGBAAt(x, y))
+				if c.A == 0 {
+					hasTransparent = true
+					continue
+				}
+				histogram[c]++
+			}
+		}
+	}
+
+	transparent = -1
+	if hasTransparent {
+		palette = append(palette, color.RGBA{})
+		transparent = 0
+		size--
+	}
+
+	colors := make([]weighted, 0, len(histogram))
+	for c, n := range histogram {
+		colors = append(colors, weighted{c, n})
+	}
+	// make the result independent of map iteration order
+	sort.Slice(colors, func(i, k int) bool {
+		a, b := colors[i].color, colors[k].color
+		if a.R != b.R {
+			return a.R < b.R
+		}
+		if a.G != b.G {
+			return a.G < b.G
+		}
+		return a.B < b.B
+	})
+
+	if len(colors) == 0 {
+		if len(palette) == 0 {
+			palette = append(palette, color.RGBA{A: 0xff})
+		}
+		return palette, transparent
+	}
+
+	boxes := []box{{colors}}
+	for len(boxes) < size {
+		// split the box with the widest channel range
+		best, bestRange := -1, 0
+		for i, b := range boxes {
+			if len(b.colors) < 2 {
+				continue
+			}
+			if _, r := b.widest(); r > bestRange {
+				best, bestRange = i, r
+			}
+		}
+		if best < 0 {
+			break
+		}
+
+		a, b := boxes[best].split()
+		boxes[best] = a
+		boxes = append(boxes, b)
+	}
+
+	for _, b := range boxes {
+		palette = append(palette, b.average())
+	}
+	return palette, transparent
+}
+
+type weighted struct {
+	color color.RGBA
+	count int
+}
+
+type box struct {
+	colors []weighted
+}
+
+func channel(c color.RGBA, k int) uint8 {
+	switch k {
+	case 0:
+		return c.R
+	case 1:
+		return c.G
+	default:
+		return c.B
+	}
+}
+
+// widest returns the channel with the largest range.
+func (b box) widest() (k, r int) {
+	for ch := 0; ch < 3; ch++ {
+		lo, hi := uint8(0xff), uint8(0)
+		for _, c := range b.colors {
+			v := channel(c.color, ch)
+			if v < lo {
+				lo = v
+			}
+			if v > hi {
+				hi = v
+			}
+		}
+		if int(hi)-int(lo) > r {
+			k, r = ch, int(hi)-int(lo)
+		}
+	}
+	return k, r
+}
+
+// split splits the box at the weighted median of the widest channel.
+func (b box) split() (box, box) {
+	k, _ := b.widest()
+	sort.SliceStable(b.colors, func(i, j int) bool {
+		return channel(b.colors[i].color, k) < channel(b.colors[j].color, k)
+	})
+
+	total := 0
+	for _, c := range b.colors {
+		total += c.count
+	}
+
+	median, sum := 1, 0
+	for i, c := range b.colors[:len(b.colors)-1] {
+		sum += c.count
+		median = i + 1
+		if sum*2 >= total {
+			break
+		}
+	}
+
+	return box{b.colors[:median]}, box{b.colors[median:]}
+}
+
+// average returns the weighted average color of the box.
+func (b box) average() color.RGBA {
+	var r, g, bl, n int
+	for _, c := range b.colors {
+		r += int(c.color.R) * c.count
+		g += int(c.color.G) * c.count
+		bl += int(c.color.B) * c.count
+		n += c.count
+	}
+	return color.RGBA{
+		R: uint8((r + n/2) / n),
+		G: uint8((g + n/2) / n),
+		B: uint8((bl + n/2) / n),
+		A: 0xff,
+	}
+}
@@ -0,0 +1,177 @@
+// preview renders an animation of a character into a looping GIF or APNG.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/adinfinit/spine-examples/animation"
+	"github.com/adinfinit/spine-examples/character"
+	"github.com/adinfinit/spine-examples/headless"
+)
+
+var (
+	assetsDir    = flag.String("assets", "../animation", "animation directory")
+	preferBinary = flag.Bool("binary", false, "load binary .skel exports when available")
+	pma          = flag.Bool("pma", false, "use premultiplied alpha atlases when available")
+	location     = flag.String("location", "", "character name, as listed by -list")
+	list         = flag.Bool("list", false, "list characters, skins and animations")
+	skin         = flag.String("skin", "", "skin name, defaults to the character default")
+	anim         = flag.String("animation", "", "animation name, defaults to the character default")
+	fps          = flag.Float64("fps", 30, "frames per second")
+	scale        = flag.Float64("scale", 0, "skeleton scale, defaults to the character scale")
+	padding      = flag.Int("padding", 4, "padding around the skeleton in pixels")
+	background   = flag.String("background", "", "background color as #rrggbb, transparent by default")
+	output       = flag.String("o", "preview.gif", "output file, .gif, .png or .apng")
+	debug        = flag.String("debug", "", "comma separated debug overlays: bones, regions, meshes, hulls, bounding boxes, paths, ik, center or all")
+)
+
+func main() {
+	flag.Parse()
+
+	log.SetOutput(os.Stderr)
+	locations, err := animation.LoadList(animation.Assets(*assetsDir))
+	if err != nil {
+		log.Println(err)
+	}
+	if *preferBinary {
+		animation.PreferFormat(locations, animation.FormatBinary)
+	}
+	if *pma {
+		animation.EnablePMA(locations)
+	}
+
+	renderer := headless.NewRenderer()
+
+	if *list {
+		for _, loc := range locations {
+			char, err := character.LoadCharacter(loc, renderer)
+			if err != nil {
+				log.Println(loc.Name, err)
+				continue
+			}
+			fmt.Println(loc.Name)
+			for _, skin := range char.Skeleton.Data.Skins {
+				fmt.Println("  skin:", skin.Name)
+			}
+			for _, anim := range char.Skeleton.Data.Animations {
+				fmt.Printf("  animation: %v (%.2fs)\n", anim.Name, anim.Duration)
+			}
+		}
+		return
+	}
+
+	loc, ok := animation.Find(locations, *location)
+	if !ok {
+		names := []string{}
+		for _, loc := range locations {
+			names = append(names, loc.Name)
+		}
+		log.Fatalf("character %q not found, available: %v", *location, strings.Join(names, ", "))
+	}
+
+	char, err := character.LoadCharacter(loc, renderer)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// images have y pointing down
+	char.Skeleton.FlipY = true
+
+	if *skin != "" {
+		if err := char.SelectSkin(*skin); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *anim != "" {
+		if err := char.SelectAnimation(*anim); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	char.Debug, err = character.ParseDebugFlags(*debug)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var bg color.Color
+	if *background != "" {
+		c, err := animation.ParseColor(*background)
+		if err != nil {
+			log.Fatal(err)
+		}
+		bg = c
+	}
+
+	skeletonScale := *scale
+	if skeletonScale <= 0 {
+		skeletonScale = loc.Scale
+	}
+
+	times := headless.SampleTimes(float64(char.Animation().Duration), *fps)
+	min, max := headless.Measure(char, times)
+	viewport := headless.Fit(min, max, skeletonScale, *padding)
+
+	frames := make([]*image.RGBA, 0, len(times))
+	for _, t := range times {
+		headless.Pose(char, t, viewport)
+		frames = append(frames, renderer.Render(char, viewport.Bounds, bg))
+	}
+
+	duration := float64(char.Animation().Duration)
+	if err := Write(*output, frames, duration); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("%v > %v > %v: %d frames, %v, %v\n",
+		loc.Name, char.Skeleton.Skin.Name, char.Animation().Name,
+		len(frames), viewport.Bounds.Size(), *output)
+}
+
+// Delays spreads duration over count frames in the specified units per second,
+// such that rounding errors don't accumulate.
+func Delays(count int, duration float64, unitsPerSecond float64) []int {
+	delays := make([]int, count)
+	total := duration * unitsPerSecond
+	for i := range delays {
+		start := math.Round(total * float64(i) / float64(count))
+		end := math.Round(total * float64(i+1) / float64(count))
+		delays[i] = int(end - start)
+	}
+	return delays
+}
+
+// Write writes frames to path, the format is chosen by the extension.
+func Write(path string, frames []*image.RGBA, duration float64) error {
+	var encode func(w io.Writer) error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gif":
+		encode = func(w io.Writer) error {
+			return WriteGIF(w, frames, Delays(len(frames), duration, 100))
+		}
+	case ".png", ".apng":
+		encode = func(w io.Writer) error {
+			return WriteAPNG(w, frames, Delays(len(frames), duration, 1000), 1000)
+		}
+	default:
+		return fmt.Errorf("unknown format %q", filepath.Ext(path))
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := encode(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
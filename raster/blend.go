@@ -0,0 +1,114 @@
+package raster
+
+import "github.com/adinfinit/spine"
+
+// Color is a linear color with components in the 0..1 range.
+//
+// Whether it is premultiplied depends on the context.
+type Color struct{ R, G, B, A float32 }
+
+// Premultiply converts a straight alpha color to premultiplied alpha.
+func (c Color) Premultiply() Color {
+	return Color{c.R * c.A, c.G * c.A, c.B * c.A, c.A}
+}
+
+// Mul multiplies colors component-wise.
+func (c Color) Mul(o Color) Color {
+	return Color{c.R * o.R, c.G * o.G, c.B * o.B, c.A * o.A}
+}
+
+// Factor is a blending factor, matching glBlendFunc.
+type Factor int
+
+const (
+	Zero Factor = iota
+	One
+	SrcColor
+	OneMinusSrcColor
+	SrcAlpha
+	OneMinusSrcAlpha
+	DstColor
+	OneMinusDstColor
+)
+
+// BlendFunc describes how source and destination are combined:
+//
+//	result = src * Src + dst * Dst
+type BlendFunc struct {
+	Src, Dst Factor
+	// Premultiply converts a straight source color before blending
+	Premultiply bool
+}
+
+// Blend returns the blend function for the blend mode.
+// With pma the source color is expected to be premultiplied.
+//
+// Multiply and screen have no straight alpha equivalent, with
+// straight alpha the source is premultiplied before blending.
+func Blend(mode spine.BlendMode, pma bool) BlendFunc {
+	switch mode {
+	case spine.Additive:
+		if pma {
+			return BlendFunc{Src: One, Dst: One}
+		}
+		return BlendFunc{Src: SrcAlpha, Dst: One}
+	case spine.Multiply:
+		return BlendFunc{Src: DstColor, Dst: OneMinusSrcAlpha, Premultiply: !pma}
+	case spine.Screen:
+		return BlendFunc{Src: One, Dst: OneMinusSrcColor, Premultiply: !pma}
+	default:
+		if pma {
+			return BlendFunc{Src: One, Dst: OneMinusSrcAlpha}
+		}
+		return BlendFunc{Src: SrcAlpha, Dst: OneMinusSrcAlpha}
+	}
+}
+
+// Apply blends src over dst.
+func (fn BlendFunc) Apply(src, dst Color) Color {
+	if fn.Premultiply {
+		src = src.Premultiply()
+	}
+	s := factor(fn.Src, src, dst).Mul(src)
+	d := factor(fn.Dst, src, dst).Mul(dst)
+	return Color{
+		R: clamp(s.R + d.R),
+		G: clamp(s.G + d.G),
+		B: clamp(s.B + d.B),
+		A: clamp(s.A + d.A),
+	}
+}
+
+func factor(f Factor, src, dst Color) Color {
+	switch f {
+	case Zero:
+		return Color{}
+	case One:
+		return Color{1, 1, 1, 1}
+	case SrcColor:
+		return src
+	case OneMinusSrcColor:
+		return Color{1 - src.R, 1 - src.G, 1 - src.B, 1 - src.A}
+	case SrcAlpha:
+		return Color{src.A, src.A, src.A, src.A}
+	case OneMinusSrcAlpha:
+		a := 1 - src.A
+		return Color{a, a, a, a}
+	case DstColor:
+		return dst
+	case OneMinusDstColor:
+		return Color{1 - dst.R, 1 - dst.G, 1 - dst.B, 1 - dst.A}
+	default:
+		panic("invalid blend factor")
+	}
+}
+
+func clamp(v float32) float32 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
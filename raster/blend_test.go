@@ -0,0 +1,68 @@
+package raster
+
+import (
+	"math"
+	"testing"
+
+	"github.com/adinfinit/spine"
+)
+
+func TestBlend(t *testing.T) {
+	tests := []struct {
+		mode spine.BlendMode
+		pma  bool
+		exp  BlendFunc
+	}{
+		{spine.Normal, false, BlendFunc{Src: SrcAlpha, Dst: OneMinusSrcAlpha}},
+		{spine.Normal, true, BlendFunc{Src: One, Dst: OneMinusSrcAlpha}},
+		{spine.Additive, false, BlendFunc{Src: SrcAlpha, Dst: One}},
+		{spine.Additive, true, BlendFunc{Src: One, Dst: One}},
+		{spine.Multiply, false, BlendFunc{Src: DstColor, Dst: OneMinusSrcAlpha, Premultiply: true}},
+		{spine.Multiply, true, BlendFunc{Src: DstColor, Dst: OneMinusSrcAlpha}},
+		{spine.Screen, false, BlendFunc{Src: One, Dst: OneMinusSrcColor, Premultiply: true}},
+		{spine.Screen, true, BlendFunc{Src: One, Dst: OneMinusSrcColor}},
+	}
+	for _, test := range tests {
+		if got := Blend(test.mode, test.pma); got != test.exp {
+			t.Errorf("Blend(%v, %v): got %+v, expected %+v", test.mode, test.pma, got, test.exp)
+		}
+	}
+}
+
+func TestBlendApply(t *testing.T) {
+	opaqueBlue := Color{0, 0, 1, 1}
+	gray := Color{0.5, 0.5, 0.5, 1}
+	orange := Color{1, 0.5, 0, 1}
+
+	tests := []struct {
+		name     string
+		mode     spine.BlendMode
+		pma      bool
+		src, dst Color
+		exp      Color
+	}{
+		{"normal", spine.Normal, false, Color{1, 0, 0, 0.5}, opaqueBlue, Color{0.5, 0, 0.5, 0.75}},
+		{"normal pma", spine.Normal, true, Color{0.5, 0, 0, 0.5}, opaqueBlue, Color{0.5, 0, 0.5, 1}},
+		{"normal transparent", spine.Normal, false, Color{1, 1, 1, 0}, opaqueBlue, opaqueBlue},
+		{"additive", spine.Additive, false, Color{1, 1, 0, 0.5}, Color{0.25, 0, 0, 1}, Color{0.75, 0.5, 0, 1}},
+		{"additive pma", spine.Additive, true, Color{0.5, 0.5, 0, 0.5}, Color{0.25, 0, 0, 1}, Color{0.75, 0.5, 0, 1}},
+		{"additive clamped", spine.Additive, true, Color{1, 1, 1, 1}, orange, Color{1, 1, 1, 1}},
+		{"multiply", spine.Multiply, false, gray, orange, Color{0.5, 0.25, 0, 1}},
+		{"multiply pma", spine.Multiply, true, gray, orange, Color{0.5, 0.25, 0, 1}},
+		{"multiply translucent", spine.Multiply, false, Color{0.5, 0.5, 0.5, 0.5}, orange, Color{0.75, 0.375, 0, 1}},
+		{"screen", spine.Screen, false, Color{1, 1, 1, 0.5}, Color{0, 0, 0, 1}, Color{0.5, 0.5, 0.5, 1}},
+		{"screen pma", spine.Screen, true, gray, orange, Color{1, 0.75, 0.5, 1}},
+	}
+	for _, test := range tests {
+		got := Blend(test.mode, test.pma).Apply(test.src, test.dst)
+		if !approx(got, test.exp) {
+			t.Errorf("%v: got %+v, expected %+v", test.name, got, test.exp)
+		}
+	}
+}
+
+func approx(a, b Color) bool {
+	const eps = 1e-6
+	return math.Abs(float64(a.R-b.R)) < eps && math.Abs(float64(a.G-b.G)) < eps &&
+		math.Abs(float64(a.B-b.B)) < eps && math.Abs(float64(a.A-b.A)) < eps
+}
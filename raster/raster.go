@@ -0,0 +1,229 @@
+// Package raster implements a small software rasterizer for
+// textured, coloured and blended triangles.
+//
+// It follows the same conventions as the OpenGL based backends,
+// so it can be used for headless rendering and comparisons.
+package raster
+
+import (
+	"image"
+	"image/draw"
+	"math"
+)
+
+// Texture is an image that can be sampled with normalized coordinates.
+type Texture struct {
+	pix    []uint8
+	stride int
+	width  int
+	height int
+
+	// PMA means that the texels are premultiplied
+	PMA bool
+	// Smooth enables bilinear filtering
+	Smooth bool
+}
+
+// NewTexture creates a texture from m.
+//
+// When pma is set, the texels are stored premultiplied, otherwise
+// with straight alpha. *image.RGBA is considered to be premultiplied
+// and *image.NRGBA straight, as in the image package.
+func NewTexture(m image.Image, pma bool) *Texture {
+	bounds := m.Bounds()
+	tex := &Texture{
+		width:  bounds.Dx(),
+		height: bounds.Dy(),
+		PMA:    pma,
+		Smooth: true,
+	}
+
+	if pma {
+		rgba, ok := m.(*image.RGBA)
+		if !ok || rgba.Rect.Min != (image.Point{}) {
+			rgba = image.NewRGBA(image.Rect(0, 0, tex.width, tex.height))
+			draw.Draw(rgba, rgba.Rect, m, bounds.Min, draw.Src)
+		}
+		tex.pix, tex.stride = rgba.Pix, rgba.Stride
+	} else {
+		nrgba, ok := m.(*image.NRGBA)
+		if !ok || nrgba.Rect.Min != (image.Point{}) {
+			nrgba = image.NewNRGBA(image.Rect(0, 0, tex.width, tex.height))
+			draw.Draw(nrgba, nrgba.Rect, m, bounds.Min, draw.Src)
+		}
+		tex.pix, tex.stride = nrgba.Pix, nrgba.Stride
+	}
+
+	return tex
+}
+
+// Size returns the size of the texture in pixels.
+func (tex *Texture) Size() (width, height int) { return tex.width, tex.height }
+
+func (tex *Texture) texel(x, y int) Color {
+	if x < 0 {
+		x = 0
+	} else if x >= tex.width {
+		x = tex.width - 1
+	}
+	if y < 0 {
+		y = 0
+	} else if y >= tex.height {
+		y = tex.height - 1
+	}
+
+	i := y*tex.stride + x*4
+	p := tex.pix[i : i+4 : i+4]
+	return Color{
+		R: float32(p[0]) / 0xff,
+		G: float32(p[1]) / 0xff,
+		B: float32(p[2]) / 0xff,
+		A: float32(p[3]) / 0xff,
+	}
+}
+
+// Sample samples the texture at u, v with v pointing down.
+//
+// The result is premultiplied when the texture is.
+func (tex *Texture) Sample(u, v float32) Color {
+	x := u*float32(tex.width) - 0.5
+	y := v*float32(tex.height) - 0.5
+	if !tex.Smooth {
+		return tex.texel(int(math.Floor(float64(x+0.5))), int(math.Floor(float64(y+0.5))))
+	}
+
+	x0, y0 := float32(math.Floor(float64(x))), float32(math.Floor(float64(y)))
+	fx, fy := x-x0, y-y0
+	ix, iy := int(x0), int(y0)
+
+	c00, c10 := tex.texel(ix, iy), tex.texel(ix+1, iy)
+	c01, c11 := tex.texel(ix, iy+1), tex.texel(ix+1, iy+1)
+
+	lerp := func(a, b Color, t float32) Color {
+		return Color{
+			a.R + (b.R-a.R)*t,
+			a.G + (b.G-a.G)*t,
+			a.B + (b.B-a.B)*t,
+			a.A + (b.A-a.A)*t,
+		}
+	}
+	return lerp(lerp(c00, c10, fx), lerp(c01, c11, fx), fy)
+}
+
+// Vertex is a triangle corner.
+type Vertex struct {
+	// X, Y is the position in target pixels
+	X, Y float32
+	// U, V is the normalized texture coordinate, v pointing down
+	U, V float32
+	// Color tints the texture, it must be premultiplied for PMA textures
+	Color Color
+}
+
+// DrawTriangles draws textured triangles to dst.
+//
+// The source color is the texture sample multiplied by the interpolated
+// vertex color, which is then combined with dst using blend.
+func DrawTriangles(dst *image.RGBA, tex *Texture, vertices []Vertex, indices []int, blend BlendFunc) {
+	for i := 0; i+2 < len(indices); i += 3 {
+		drawTriangle(dst, tex, &vertices[indices[i]], &vertices[indices[i+1]], &vertices[indices[i+2]], blend)
+	}
+}
+
+func drawTriangle(dst *image.RGBA, tex *Texture, a, b, c *Vertex, blend BlendFunc) {
+	area := edge(a.X, a.Y, b.X, b.Y, c.X, c.Y)
+	if area == 0 {
+		return
+	}
+
+	minx := floor(min3(a.X, b.X, c.X))
+	miny := floor(min3(a.Y, b.Y, c.Y))
+	maxx := ceil(max3(a.X, b.X, c.X))
+	maxy := ceil(max3(a.Y, b.Y, c.Y))
+
+	bounds := dst.Rect.Intersect(image.Rect(minx, miny, maxx+1, maxy+1))
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		py := float32(y) + 0.5
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			px := float32(x) + 0.5
+
+			w0 := edge(b.X, b.Y, c.X, c.Y, px, py) / area
+			w1 := edge(c.X, c.Y, a.X, a.Y, px, py) / area
+			w2 := edge(a.X, a.Y, b.X, b.Y, px, py) / area
+			if w0 < 0 || w1 < 0 || w2 < 0 {
+				continue
+			}
+			// avoid drawing shared edges twice
+			if (w0 == 0 && !topLeft(b, c, area)) ||
+				(w1 == 0 && !topLeft(c, a, area)) ||
+				(w2 == 0 && !topLeft(a, b, area)) {
+				continue
+			}
+
+			u := a.U*w0 + b.U*w1 + c.U*w2
+			v := a.V*w0 + b.V*w1 + c.V*w2
+			tint := Color{
+				R: a.Color.R*w0 + b.Color.R*w1 + c.Color.R*w2,
+				G: a.Color.G*w0 + b.Color.G*w1 + c.Color.G*w2,
+				B: a.Color.B*w0 + b.Color.B*w1 + c.Color.B*w2,
+				A: a.Color.A*w0 + b.Color.A*w1 + c.Color.A*w2,
+			}
+
+			src := tint
+			if tex != nil {
+				src = tex.Sample(u, v).Mul(tint)
+			}
+
+			i := dst.PixOffset(x, y)
+			p := dst.Pix[i : i+4 : i+4]
+			d := Color{
+				R: float32(p[0]) / 0xff,
+				G: float32(p[1]) / 0xff,
+				B: float32(p[2]) / 0xff,
+				A: float32(p[3]) / 0xff,
+			}
+
+			r := blend.Apply(src, d)
+			p[0] = uint8(r.R*0xff + 0.5)
+			p[1] = uint8(r.G*0xff + 0.5)
+			p[2] = uint8(r.B*0xff + 0.5)
+			p[3] = uint8(r.A*0xff + 0.5)
+		}
+	}
+}
+
+func edge(ax, ay, bx, by, px, py float32) float32 {
+	return (bx-ax)*(py-ay) - (by-ay)*(px-ax)
+}
+
+// topLeft implements the top-left fill rule independent of winding.
+func topLeft(a, b *Vertex, area float32) bool {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	if area < 0 {
+		dx, dy = -dx, -dy
+	}
+	return dy < 0 || (dy == 0 && dx > 0)
+}
+
+func floor(v float32) int { return int(math.Floor(float64(v))) }
+func ceil(v float32) int  { return int(math.Ceil(float64(v))) }
+
+func min3(a, b, c float32) float32 {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+func max3(a, b, c float32) float32 {
+	if b > a {
+		a = b
+	}
+	if c > a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,50 @@
+package skel
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+// object is a JSON object that keeps the insertion order of keys.
+type object struct {
+	keys   []string
+	values []interface{}
+}
+
+func (obj *object) set(key string, value interface{}) {
+	obj.keys = append(obj.keys, key)
+	obj.values = append(obj.values, value)
+}
+
+func (obj *object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range obj.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+
+		v, err := json.Marshal(obj.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// number is formatted with the shortest representation that
+// round-trips to the same float32.
+type number float32
+
+func (v number) MarshalJSON() ([]byte, error) {
+	return strconv.AppendFloat(nil, float64(v), 'g', -1, 32), nil
+}
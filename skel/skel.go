@@ -0,0 +1,912 @@
+// Package skel converts binary Spine 3.6 skeletons (.skel) into the JSON
+// export format, so they can be loaded with spine.ReadJSON.
+package skel
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/adinfinit/spine"
+)
+
+const (
+	attachmentRegion = iota
+	attachmentBoundingBox
+	attachmentMesh
+	attachmentLinkedMesh
+	attachmentPath
+	attachmentPoint
+	attachmentClipping
+)
+
+const (
+	curveLinear = iota
+	curveStepped
+	curveBezier
+)
+
+const (
+	boneRotate = iota
+	boneTranslate
+	boneScale
+	boneShear
+)
+
+const (
+	slotAttachment = iota
+	slotColor
+	slotTwoColor
+)
+
+const (
+	pathPosition = iota
+	pathSpacing
+	pathMix
+)
+
+var (
+	transformModes = []string{"normal", "onlyTranslation", "noRotationOrReflection", "noScale", "noScaleOrReflection"}
+	blendModes     = []string{"normal", "additive", "multiply", "screen"}
+	positionModes  = []string{"fixed", "percent"}
+	spacingModes   = []string{"length", "fixed", "percent"}
+	rotateModes    = []string{"tangent", "chain", "chainScale"}
+)
+
+// ErrUnexpectedEOF is returned when the binary ends prematurely.
+var ErrUnexpectedEOF = errors.New("skel: unexpected end of data")
+
+// ReadBinary reads skeleton data from a binary export.
+func ReadBinary(rd io.Reader) (*spine.SkeletonData, error) {
+	data, err := io.ReadAll(rd)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := ToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return spine.ReadJSON(bytes.NewReader(content))
+}
+
+// ToJSON converts a binary skeleton into the equivalent JSON export.
+func ToJSON(data []byte) (result []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if rerr, ok := r.(decodeError); ok {
+				result, err = nil, rerr.err
+				return
+			}
+			panic(r)
+		}
+	}()
+
+	dec := &decoder{data: data}
+	return json.Marshal(dec.skeleton())
+}
+
+// decodeError is used to unwind the decoder on malformed input.
+type decodeError struct{ err error }
+
+type decoder struct {
+	data []byte
+	pos  int
+
+	nonessential bool
+
+	bones      []string
+	slots      []string
+	ik         []string
+	transforms []string
+	paths      []string
+	skins      []string
+	events     []*event
+}
+
+type event struct {
+	name   string
+	int    int
+	float  float32
+	string *string
+}
+
+func (dec *decoder) fail(format string, args ...interface{}) {
+	panic(decodeError{fmt.Errorf("skel: "+format, args...)})
+}
+
+func (dec *decoder) byte() byte {
+	if dec.pos >= len(dec.data) {
+		panic(decodeError{ErrUnexpectedEOF})
+	}
+	b := dec.data[dec.pos]
+	dec.pos++
+	return b
+}
+
+func (dec *decoder) bool() bool { return dec.byte() != 0 }
+
+func (dec *decoder) int32() int32 {
+	v := uint32(dec.byte()) << 24
+	v |= uint32(dec.byte()) << 16
+	v |= uint32(dec.byte()) << 8
+	v |= uint32(dec.byte())
+	return int32(v)
+}
+
+func (dec *decoder) varint(optimizePositive bool) int {
+	var v uint32
+	for shift := uint(0); shift < 35; shift += 7 {
+		b := dec.byte()
+		v |= uint32(b&0x7F) << shift
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	if !optimizePositive {
+		v = (v >> 1) ^ -(v & 1)
+	}
+	return int(int32(v))
+}
+
+// count reads a length and verifies that it's plausible.
+func (dec *decoder) count() int {
+	n := dec.varint(true)
+	if n < 0 || n > len(dec.data)-dec.pos {
+		dec.fail("invalid count %d at %d", n, dec.pos)
+	}
+	return n
+}
+
+func (dec *decoder) float() float32 {
+	return math.Float32frombits(uint32(dec.int32()))
+}
+
+func (dec *decoder) floats(n int) []number {
+	xs := make([]number, n)
+	for i := range xs {
+		xs[i] = number(dec.float())
+	}
+	return xs
+}
+
+func (dec *decoder) shorts() []int {
+	xs := make([]int, dec.count())
+	for i := range xs {
+		xs[i] = int(dec.byte())<<8 | int(dec.byte())
+	}
+	return xs
+}
+
+// string returns nil for null strings.
+func (dec *decoder) string() *string {
+	// the length is stored plus one, zero means null
+	n := dec.varint(true)
+	if n == 0 {
+		return nil
+	}
+	n--
+	if n < 0 {
+		dec.fail("invalid string length %d at %d", n, dec.pos)
+	}
+	if n > len(dec.data)-dec.pos {
+		panic(decodeError{ErrUnexpectedEOF})
+	}
+	s := string(dec.data[dec.pos : dec.pos+n])
+	dec.pos += n
+	return &s
+}
+
+func (dec *decoder) name() string {
+	s := dec.string()
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func (dec *decoder) color() string {
+	return fmt.Sprintf("%02x%02x%02x%02x", dec.byte(), dec.byte(), dec.byte(), dec.byte())
+}
+
+func (dec *decoder) index(names []string, kind string) string {
+	i := dec.varint(true)
+	if i < 0 || i >= len(names) {
+		dec.fail("invalid %v index %d", kind, i)
+	}
+	return names[i]
+}
+
+func (dec *decoder) skeleton() *object {
+	root := &object{}
+
+	skeleton := &object{}
+	if hash := dec.name(); hash != "" {
+		skeleton.set("hash", hash)
+	}
+	if version := dec.name(); version != "" {
+		skeleton.set("spine", version)
+	}
+	skeleton.set("width", number(dec.float()))
+	skeleton.set("height", number(dec.float()))
+
+	dec.nonessential = dec.bool()
+	if dec.nonessential {
+		if fps := dec.float(); fps != 30 {
+			skeleton.set("fps", number(fps))
+		}
+		if images := dec.string(); images != nil {
+			skeleton.set("images", *images)
+		}
+	}
+	root.set("skeleton", skeleton)
+
+	root.set("bones", dec.readBones())
+	root.set("slots", dec.readSlots())
+	if ik := dec.readIK(); len(ik) > 0 {
+		root.set("ik", ik)
+	}
+	if transform := dec.readTransforms(); len(transform) > 0 {
+		root.set("transform", transform)
+	}
+	if path := dec.readPaths(); len(path) > 0 {
+		root.set("path", path)
+	}
+	root.set("skins", dec.readSkins())
+	if events := dec.readEvents(); len(events.keys) > 0 {
+		root.set("events", events)
+	}
+	root.set("animations", dec.readAnimations())
+
+	return root
+}
+
+func (dec *decoder) readBones() []interface{} {
+	var bones []interface{}
+	for i, n := 0, dec.count(); i < n; i++ {
+		bone := &object{}
+		name := dec.name()
+		bone.set("name", name)
+		if i > 0 {
+			bone.set("parent", dec.index(dec.bones, "bone"))
+		}
+		dec.bones = append(dec.bones, name)
+
+		rotation, x, y := dec.float(), dec.float(), dec.float()
+		scaleX, scaleY := dec.float(), dec.float()
+		shearX, shearY := dec.float(), dec.float()
+		length := dec.float()
+
+		setFloat(bone, "length", length, 0)
+		setFloat(bone, "rotation", rotation, 0)
+		setFloat(bone, "x", x, 0)
+		setFloat(bone, "y", y, 0)
+		setFloat(bone, "scaleX", scaleX, 1)
+		setFloat(bone, "scaleY", scaleY, 1)
+		setFloat(bone, "shearX", shearX, 0)
+		setFloat(bone, "shearY", shearY, 0)
+
+		mode := dec.varint(true)
+		if mode < 0 || mode >= len(transformModes) {
+			dec.fail("invalid transform mode %d for %q", mode, name)
+		}
+		if mode != 0 {
+			bone.set("transform", transformModes[mode])
+		}
+
+		if dec.nonessential {
+			if color := dec.color(); color != "9b9b9bff" {
+				bone.set("color", color)
+			}
+		}
+		bones = append(bones, bone)
+	}
+	return bones
+}
+
+func (dec *decoder) readSlots() []interface{} {
+	var slots []interface{}
+	for i, n := 0, dec.count(); i < n; i++ {
+		slot := &object{}
+		name := dec.name()
+		dec.slots = append(dec.slots, name)
+		slot.set("name", name)
+		slot.set("bone", dec.index(dec.bones, "bone"))
+
+		if color := dec.color(); color != "ffffffff" {
+			slot.set("color", color)
+		}
+		a, r, g, b := dec.byte(), dec.byte(), dec.byte(), dec.byte()
+		if !(r == 0xff && g == 0xff && b == 0xff && a == 0xff) {
+			slot.set("dark", fmt.Sprintf("%02x%02x%02x", r, g, b))
+		}
+		if attachment := dec.string(); attachment != nil {
+			slot.set("attachment", *attachment)
+		}
+
+		blend := dec.varint(true)
+		if blend < 0 || blend >= len(blendModes) {
+			dec.fail("invalid blend mode %d for %q", blend, name)
+		}
+		if blend != 0 {
+			slot.set("blend", blendModes[blend])
+		}
+		slots = append(slots, slot)
+	}
+	return slots
+}
+
+func (dec *decoder) constraintBones() []string {
+	bones := make([]string, dec.count())
+	for i := range bones {
+		bones[i] = dec.index(dec.bones, "bone")
+	}
+	return bones
+}
+
+func (dec *decoder) readIK() []interface{} {
+	var constraints []interface{}
+	for i, n := 0, dec.count(); i < n; i++ {
+		constraint := &object{}
+		name := dec.name()
+		dec.ik = append(dec.ik, name)
+		constraint.set("name", name)
+		constraint.set("order", dec.varint(true))
+		constraint.set("bones", dec.constraintBones())
+		constraint.set("target", dec.index(dec.bones, "bone"))
+		setFloat(constraint, "mix", dec.float(), 1)
+		if int8(dec.byte()) != 1 {
+			constraint.set("bendPositive", false)
+		}
+		constraints = append(constraints, constraint)
+	}
+	return constraints
+}
+
+func (dec *decoder) readTransforms() []interface{} {
+	var constraints []interface{}
+	for i, n := 0, dec.count(); i < n; i++ {
+		constraint := &object{}
+		name := dec.name()
+		dec.transforms = append(dec.transforms, name)
+		constraint.set("name", name)
+		constraint.set("order", dec.varint(true))
+		constraint.set("bones", dec.constraintBones())
+		constraint.set("target", dec.index(dec.bones, "bone"))
+
+		local, relative := dec.bool(), dec.bool()
+
+		setFloat(constraint, "rotation", dec.float(), 0)
+		setFloat(constraint, "x", dec.float(), 0)
+		setFloat(constraint, "y", dec.float(), 0)
+		setFloat(constraint, "scaleX", dec.float(), 0)
+		setFloat(constraint, "scaleY", dec.float(), 0)
+		setFloat(constraint, "shearY", dec.float(), 0)
+		setFloat(constraint, "rotateMix", dec.float(), 1)
+		setFloat(constraint, "translateMix", dec.float(), 1)
+		setFloat(constraint, "scaleMix", dec.float(), 1)
+		setFloat(constraint, "shearMix", dec.float(), 1)
+
+		if local {
+			constraint.set("local", true)
+		}
+		if relative {
+			constraint.set("relative", true)
+		}
+		constraints = append(constraints, constraint)
+	}
+	return constraints
+}
+
+func (dec *decoder) readPaths() []interface{} {
+	var constraints []interface{}
+	for i, n := 0, dec.count(); i < n; i++ {
+		constraint := &object{}
+		name := dec.name()
+		dec.paths = append(dec.paths, name)
+		constraint.set("name", name)
+		constraint.set("order", dec.varint(true))
+		constraint.set("bones", dec.constraintBones())
+		constraint.set("target", dec.index(dec.slots, "slot"))
+
+		setMode(dec, constraint, "positionMode", positionModes, 1)
+		setMode(dec, constraint, "spacingMode", spacingModes, 0)
+		setMode(dec, constraint, "rotateMode", rotateModes, 0)
+
+		setFloat(constraint, "rotation", dec.float(), 0)
+		setFloat(constraint, "position", dec.float(), 0)
+		setFloat(constraint, "spacing", dec.float(), 0)
+		setFloat(constraint, "rotateMix", dec.float(), 1)
+		setFloat(constraint, "translateMix", dec.float(), 1)
+		constraints = append(constraints, constraint)
+	}
+	return constraints
+}
+
+func (dec *decoder) readSkins() *object {
+	skins := &object{}
+	if skin := dec.readSkin(); skin != nil {
+		dec.skins = append(dec.skins, "default")
+		skins.set("default", skin)
+	}
+	for i, n := 0, dec.count(); i < n; i++ {
+		name := dec.name()
+		dec.skins = append(dec.skins, name)
+		skin := dec.readSkin()
+		if skin == nil {
+			skin = &object{}
+		}
+		skins.set(name, skin)
+	}
+	return skins
+}
+
+func (dec *decoder) readSkin() *object {
+	slotCount := dec.count()
+	if slotCount == 0 {
+		return nil
+	}
+
+	skin := &object{}
+	for i := 0; i < slotCount; i++ {
+		slot := dec.index(dec.slots, "slot")
+		attachments := &object{}
+		for k, n := 0, dec.count(); k < n; k++ {
+			name := dec.name()
+			attachments.set(name, dec.readAttachment(name))
+		}
+		skin.set(slot, attachments)
+	}
+	return skin
+}
+
+func (dec *decoder) readAttachment(key string) *object {
+	attachment := &object{}
+	if name := dec.string(); name != nil && *name != key {
+		attachment.set("name", *name)
+	}
+
+	kind := dec.byte()
+	switch kind {
+	case attachmentRegion:
+		if path := dec.string(); path != nil {
+			attachment.set("path", *path)
+		}
+		rotation, x, y := dec.float(), dec.float(), dec.float()
+		scaleX, scaleY := dec.float(), dec.float()
+		width, height := dec.float(), dec.float()
+		setFloat(attachment, "x", x, 0)
+		setFloat(attachment, "y", y, 0)
+		setFloat(attachment, "scaleX", scaleX, 1)
+		setFloat(attachment, "scaleY", scaleY, 1)
+		setFloat(attachment, "rotation", rotation, 0)
+		attachment.set("width", number(width))
+		attachment.set("height", number(height))
+		if color := dec.color(); color != "ffffffff" {
+			attachment.set("color", color)
+		}
+
+	case attachmentBoundingBox:
+		attachment.set("type", "boundingbox")
+		vertexCount := dec.count()
+		attachment.set("vertexCount", vertexCount)
+		attachment.set("vertices", dec.vertices(vertexCount))
+		dec.nonessentialColor(attachment, "60f000ff")
+
+	case attachmentMesh:
+		attachment.set("type", "mesh")
+		if path := dec.string(); path != nil {
+			attachment.set("path", *path)
+		}
+		color := dec.color()
+		vertexCount := dec.count()
+		attachment.set("uvs", dec.floats(vertexCount*2))
+		attachment.set("triangles", dec.shorts())
+		attachment.set("vertices", dec.vertices(vertexCount))
+		attachment.set("hull", dec.varint(true))
+		if dec.nonessential {
+			attachment.set("edges", dec.shorts())
+			attachment.set("width", number(dec.float()))
+			attachment.set("height", number(dec.float()))
+		}
+		if color != "ffffffff" {
+			attachment.set("color", color)
+		}
+
+	case attachmentLinkedMesh:
+		attachment.set("type", "linkedmesh")
+		if path := dec.string(); path != nil {
+			attachment.set("path", *path)
+		}
+		color := dec.color()
+		if skin := dec.string(); skin != nil {
+			attachment.set("skin", *skin)
+		}
+		attachment.set("parent", dec.name())
+		if !dec.bool() {
+			attachment.set("deform", false)
+		}
+		if dec.nonessential {
+			attachment.set("width", number(dec.float()))
+			attachment.set("height", number(dec.float()))
+		}
+		if color != "ffffffff" {
+			attachment.set("color", color)
+		}
+
+	case attachmentPath:
+		attachment.set("type", "path")
+		if dec.bool() {
+			attachment.set("closed", true)
+		}
+		if !dec.bool() {
+			attachment.set("constantSpeed", false)
+		}
+		vertexCount := dec.count()
+		vertices := dec.vertices(vertexCount)
+		attachment.set("lengths", dec.floats(vertexCount/3))
+		attachment.set("vertexCount", vertexCount)
+		attachment.set("vertices", vertices)
+		dec.nonessentialColor(attachment, "ff7f00ff")
+
+	case attachmentPoint:
+		attachment.set("type", "point")
+		rotation, x, y := dec.float(), dec.float(), dec.float()
+		setFloat(attachment, "x", x, 0)
+		setFloat(attachment, "y", y, 0)
+		setFloat(attachment, "rotation", rotation, 0)
+		dec.nonessentialColor(attachment, "f1f100ff")
+
+	case attachmentClipping:
+		attachment.set("type", "clipping")
+		attachment.set("end", dec.index(dec.slots, "slot"))
+		vertexCount := dec.count()
+		attachment.set("vertexCount", vertexCount)
+		attachment.set("vertices", dec.vertices(vertexCount))
+		dec.nonessentialColor(attachment, "")
+
+	default:
+		dec.fail("unknown attachment type %d for %q", kind, key)
+	}
+
+	return attachment
+}
+
+// nonessentialColor reads the editor color of an attachment,
+// def is the color that the exporter omits.
+func (dec *decoder) nonessentialColor(attachment *object, def string) {
+	if !dec.nonessential {
+		return
+	}
+	if color := dec.color(); color != def {
+		attachment.set("color", color)
+	}
+}
+
+// vertices reads unweighted vertices as [x, y, ...]
+// and weighted as [boneCount, bone, x, y, weight, ...].
+func (dec *decoder) vertices(vertexCount int) []number {
+	if !dec.bool() {
+		return dec.floats(vertexCount * 2)
+	}
+
+	var vertices []number
+	for i := 0; i < vertexCount; i++ {
+		boneCount := dec.count()
+		vertices = append(vertices, number(boneCount))
+		for k := 0; k < boneCount; k++ {
+			bone := dec.varint(true)
+			if bone < 0 || bone >= len(dec.bones) {
+				dec.fail("invalid vertex bone %d", bone)
+			}
+			vertices = append(vertices, number(bone))
+			vertices = append(vertices, number(dec.float()), number(dec.float()), number(dec.float()))
+		}
+	}
+	return vertices
+}
+
+func (dec *decoder) readEvents() *object {
+	events := &object{}
+	for i, n := 0, dec.count(); i < n; i++ {
+		ev := &event{}
+		ev.name = dec.name()
+		ev.int = dec.varint(false)
+		ev.float = dec.float()
+		ev.string = dec.string()
+		dec.events = append(dec.events, ev)
+
+		data := &object{}
+		if ev.int != 0 {
+			data.set("int", ev.int)
+		}
+		setFloat(data, "float", ev.float, 0)
+		if ev.string != nil && *ev.string != "" {
+			data.set("string", *ev.string)
+		}
+		events.set(ev.name, data)
+	}
+	return events
+}
+
+func (dec *decoder) readAnimations() *object {
+	animations := &object{}
+	for i, n := 0, dec.count(); i < n; i++ {
+		name := dec.name()
+		animations.set(name, dec.readAnimation())
+	}
+	return animations
+}
+
+func (dec *decoder) readAnimation() *object {
+	animation := &object{}
+
+	if slots := dec.readSlotTimelines(); len(slots.keys) > 0 {
+		animation.set("slots", slots)
+	}
+	if bones := dec.readBoneTimelines(); len(bones.keys) > 0 {
+		animation.set("bones", bones)
+	}
+	if ik := dec.readIKTimelines(); len(ik.keys) > 0 {
+		animation.set("ik", ik)
+	}
+	if transform := dec.readTransformTimelines(); len(transform.keys) > 0 {
+		animation.set("transform", transform)
+	}
+	if paths := dec.readPathTimelines(); len(paths.keys) > 0 {
+		animation.set("paths", paths)
+	}
+	if deform := dec.readDeformTimelines(); len(deform.keys) > 0 {
+		animation.set("deform", deform)
+	}
+	if drawOrder := dec.readDrawOrder(); len(drawOrder) > 0 {
+		animation.set("drawOrder", drawOrder)
+	}
+	if events := dec.readEventTimeline(); len(events) > 0 {
+		animation.set("events", events)
+	}
+
+	return animation
+}
+
+// frames reads a timeline where frame reads the values of a single key.
+func (dec *decoder) frames(curves bool, frame func(key *object)) []interface{} {
+	frameCount := dec.count()
+	keys := make([]interface{}, frameCount)
+	for i := range keys {
+		key := &object{}
+		key.set("time", number(dec.float()))
+		frame(key)
+		if curves && i < frameCount-1 {
+			dec.curve(key)
+		}
+		keys[i] = key
+	}
+	return keys
+}
+
+func (dec *decoder) curve(key *object) {
+	switch kind := dec.byte(); kind {
+	case curveLinear:
+	case curveStepped:
+		key.set("curve", "stepped")
+	case curveBezier:
+		key.set("curve", dec.floats(4))
+	default:
+		dec.fail("unknown curve type %d", kind)
+	}
+}
+
+func (dec *decoder) readSlotTimelines() *object {
+	slots := &object{}
+	for i, n := 0, dec.count(); i < n; i++ {
+		slot := dec.index(dec.slots, "slot")
+		timelines := &object{}
+		for k, nk := 0, dec.count(); k < nk; k++ {
+			switch kind := dec.byte(); kind {
+			case slotAttachment:
+				timelines.set("attachment", dec.frames(false, func(key *object) {
+					key.set("name", dec.string())
+				}))
+			case slotColor:
+				timelines.set("color", dec.frames(true, func(key *object) {
+					key.set("color", dec.color())
+				}))
+			case slotTwoColor:
+				timelines.set("twoColor", dec.frames(true, func(key *object) {
+					key.set("light", dec.color())
+					dark := dec.color()
+					key.set("dark", dark[2:])
+				}))
+			default:
+				dec.fail("invalid timeline type %d for slot %q", kind, slot)
+			}
+		}
+		slots.set(slot, timelines)
+	}
+	return slots
+}
+
+func (dec *decoder) readBoneTimelines() *object {
+	bones := &object{}
+	for i, n := 0, dec.count(); i < n; i++ {
+		bone := dec.index(dec.bones, "bone")
+		timelines := &object{}
+		for k, nk := 0, dec.count(); k < nk; k++ {
+			switch kind := dec.byte(); kind {
+			case boneRotate:
+				timelines.set("rotate", dec.frames(true, func(key *object) {
+					key.set("angle", number(dec.float()))
+				}))
+			case boneTranslate, boneScale, boneShear:
+				name := map[byte]string{
+					boneTranslate: "translate",
+					boneScale:     "scale",
+					boneShear:     "shear",
+				}[kind]
+				timelines.set(name, dec.frames(true, func(key *object) {
+					key.set("x", number(dec.float()))
+					key.set("y", number(dec.float()))
+				}))
+			default:
+				dec.fail("invalid timeline type %d for bone %q", kind, bone)
+			}
+		}
+		bones.set(bone, timelines)
+	}
+	return bones
+}
+
+func (dec *decoder) readIKTimelines() *object {
+	constraints := &object{}
+	for i, n := 0, dec.count(); i < n; i++ {
+		name := dec.index(dec.ik, "ik constraint")
+		constraints.set(name, dec.frames(true, func(key *object) {
+			setFloat(key, "mix", dec.float(), 1)
+			if int8(dec.byte()) != 1 {
+				key.set("bendPositive", false)
+			}
+		}))
+	}
+	return constraints
+}
+
+func (dec *decoder) readTransformTimelines() *object {
+	constraints := &object{}
+	for i, n := 0, dec.count(); i < n; i++ {
+		name := dec.index(dec.transforms, "transform constraint")
+		constraints.set(name, dec.frames(true, func(key *object) {
+			setFloat(key, "rotateMix", dec.float(), 1)
+			setFloat(key, "translateMix", dec.float(), 1)
+			setFloat(key, "scaleMix", dec.float(), 1)
+			setFloat(key, "shearMix", dec.float(), 1)
+		}))
+	}
+	return constraints
+}
+
+func (dec *decoder) readPathTimelines() *object {
+	constraints := &object{}
+	for i, n := 0, dec.count(); i < n; i++ {
+		name := dec.index(dec.paths, "path constraint")
+		timelines := &object{}
+		for k, nk := 0, dec.count(); k < nk; k++ {
+			switch kind := dec.byte(); kind {
+			case pathPosition:
+				timelines.set("position", dec.frames(true, func(key *object) {
+					setFloat(key, "position", dec.float(), 0)
+				}))
+			case pathSpacing:
+				timelines.set("spacing", dec.frames(true, func(key *object) {
+					setFloat(key, "spacing", dec.float(), 0)
+				}))
+			case pathMix:
+				timelines.set("mix", dec.frames(true, func(key *object) {
+					setFloat(key, "rotateMix", dec.float(), 1)
+					setFloat(key, "translateMix", dec.float(), 1)
+				}))
+			default:
+				dec.fail("invalid timeline type %d for path %q", kind, name)
+			}
+		}
+		constraints.set(name, timelines)
+	}
+	return constraints
+}
+
+func (dec *decoder) readDeformTimelines() *object {
+	skins := &object{}
+	for i, n := 0, dec.count(); i < n; i++ {
+		skin := dec.index(dec.skins, "skin")
+		slots := &object{}
+		for k, nk := 0, dec.count(); k < nk; k++ {
+			slot := dec.index(dec.slots, "slot")
+			attachments := &object{}
+			for j, nj := 0, dec.count(); j < nj; j++ {
+				attachment := dec.name()
+				attachments.set(attachment, dec.frames(true, func(key *object) {
+					end := dec.count()
+					if end == 0 {
+						return
+					}
+					start := dec.count()
+					if start != 0 {
+						key.set("offset", start)
+					}
+					key.set("vertices", dec.floats(end))
+				}))
+			}
+			slots.set(slot, attachments)
+		}
+		skins.set(skin, slots)
+	}
+	return skins
+}
+
+func (dec *decoder) readDrawOrder() []interface{} {
+	var keys []interface{}
+	for i, n := 0, dec.count(); i < n; i++ {
+		key := &object{}
+		key.set("time", number(dec.float()))
+		offsetCount := dec.count()
+		if offsetCount > 0 {
+			offsets := make([]interface{}, offsetCount)
+			for k := range offsets {
+				offset := &object{}
+				offset.set("slot", dec.index(dec.slots, "slot"))
+				offset.set("offset", dec.varint(true))
+				offsets[k] = offset
+			}
+			key.set("offsets", offsets)
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (dec *decoder) readEventTimeline() []interface{} {
+	var keys []interface{}
+	for i, n := 0, dec.count(); i < n; i++ {
+		key := &object{}
+		key.set("time", number(dec.float()))
+
+		index := dec.varint(true)
+		if index < 0 || index >= len(dec.events) {
+			dec.fail("invalid event index %d", index)
+		}
+		ev := dec.events[index]
+		key.set("name", ev.name)
+
+		if value := dec.varint(false); value != ev.int {
+			key.set("int", value)
+		}
+		if value := dec.float(); value != ev.float {
+			key.set("float", number(value))
+		}
+		if dec.bool() {
+			key.set("string", dec.string())
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func setFloat(obj *object, key string, value, def float32) {
+	if value != def {
+		obj.set(key, number(value))
+	}
+}
+
+func setMode(dec *decoder, obj *object, key string, modes []string, def int) {
+	mode := dec.varint(true)
+	if mode < 0 || mode >= len(modes) {
+		dec.fail("invalid %v %d", key, mode)
+	}
+	if mode != def {
+		obj.set(key, modes[mode])
+	}
+}
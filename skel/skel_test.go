@@ -0,0 +1,151 @@
+package skel
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// decode runs read on data and returns the decoding error.
+func decode(data []byte, read func(dec *decoder)) (dec *decoder, err error) {
+	dec = &decoder{data: data}
+	defer func() {
+		if r := recover(); r != nil {
+			rerr, ok := r.(decodeError)
+			if !ok {
+				panic(r)
+			}
+			err = rerr.err
+		}
+	}()
+	read(dec)
+	return dec, nil
+}
+
+func TestVarint(t *testing.T) {
+	tests := []struct {
+		data             []byte
+		optimizePositive bool
+		exp              int
+	}{
+		{[]byte{0x00}, true, 0},
+		{[]byte{0x7F}, true, 127},
+		{[]byte{0x80, 0x01}, true, 128},
+		{[]byte{0xAC, 0x02}, true, 300},
+		{[]byte{0xFF, 0xFF, 0xFF, 0xFF, 0x07}, true, 1<<31 - 1},
+		{[]byte{0xFF, 0xFF, 0xFF, 0xFF, 0x0F}, true, -1},
+		// zigzag encoding
+		{[]byte{0x00}, false, 0},
+		{[]byte{0x01}, false, -1},
+		{[]byte{0x02}, false, 1},
+		{[]byte{0x03}, false, -2},
+		{[]byte{0xD7, 0x04}, false, -300},
+		{[]byte{0xFE, 0xFF, 0xFF, 0xFF, 0x0F}, false, 1<<31 - 1},
+		{[]byte{0xFF, 0xFF, 0xFF, 0xFF, 0x0F}, false, -1 << 31},
+	}
+	for _, test := range tests {
+		var got int
+		dec, err := decode(test.data, func(dec *decoder) { got = dec.varint(test.optimizePositive) })
+		if err != nil {
+			t.Errorf("% x: %v", test.data, err)
+			continue
+		}
+		if got != test.exp {
+			t.Errorf("% x %v: got %d, expected %d", test.data, test.optimizePositive, got, test.exp)
+		}
+		if dec.pos != len(test.data) {
+			t.Errorf("% x: read %d bytes", test.data, dec.pos)
+		}
+	}
+
+	if _, err := decode([]byte{0x80, 0x80}, func(dec *decoder) { dec.varint(true) }); err != ErrUnexpectedEOF {
+		t.Errorf("truncated varint: got %v", err)
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		data []byte
+		exp  *string
+	}{
+		{[]byte{0x00}, nil},
+		{[]byte{0x01}, new(string)},
+		{[]byte{0x04, 'a', 'b', 'c'}, func() *string { s := "abc"; return &s }()},
+	}
+	for _, test := range tests {
+		var got *string
+		_, err := decode(test.data, func(dec *decoder) { got = dec.string() })
+		if err != nil {
+			t.Errorf("% x: %v", test.data, err)
+			continue
+		}
+		if (got == nil) != (test.exp == nil) || got != nil && *got != *test.exp {
+			t.Errorf("% x: got %v, expected %v", test.data, got, test.exp)
+		}
+	}
+
+	if _, err := decode([]byte{0x04, 'a'}, func(dec *decoder) { dec.string() }); err != ErrUnexpectedEOF {
+		t.Errorf("truncated string: got %v", err)
+	}
+
+	var name string
+	if _, err := decode([]byte{0x00}, func(dec *decoder) { name = dec.name() }); err != nil || name != "" {
+		t.Errorf("null name: got %q %v", name, err)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	names := []string{"root", "hip", "torso"}
+
+	var got string
+	if _, err := decode([]byte{0x02}, func(dec *decoder) { got = dec.index(names, "bone") }); err != nil || got != "torso" {
+		t.Errorf("got %q %v, expected torso", got, err)
+	}
+	if _, err := decode([]byte{0x03}, func(dec *decoder) { dec.index(names, "bone") }); err == nil || !strings.Contains(err.Error(), "invalid bone index 3") {
+		t.Errorf("out of range: got %v", err)
+	}
+}
+
+func TestCurve(t *testing.T) {
+	bezier := []byte{
+		curveBezier,
+		0x3E, 0x80, 0x00, 0x00, // 0.25
+		0x00, 0x00, 0x00, 0x00, // 0
+		0x3F, 0x40, 0x00, 0x00, // 0.75
+		0x3F, 0x80, 0x00, 0x00, // 1
+	}
+	tests := []struct {
+		name string
+		data []byte
+		exp  string
+	}{
+		{"linear", []byte{curveLinear}, `{}`},
+		{"stepped", []byte{curveStepped}, `{"curve":"stepped"}`},
+		{"bezier", bezier, `{"curve":[0.25,0,0.75,1]}`},
+	}
+	for _, test := range tests {
+		key := &object{}
+		dec, err := decode(test.data, func(dec *decoder) { dec.curve(key) })
+		if err != nil {
+			t.Errorf("%v: %v", test.name, err)
+			continue
+		}
+		got, err := json.Marshal(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != test.exp {
+			t.Errorf("%v: got %s, expected %s", test.name, got, test.exp)
+		}
+		if dec.pos != len(test.data) {
+			t.Errorf("%v: read %d bytes", test.name, dec.pos)
+		}
+	}
+
+	if _, err := decode([]byte{7}, func(dec *decoder) { dec.curve(&object{}) }); err == nil || !strings.Contains(err.Error(), "unknown curve type 7") {
+		t.Errorf("unknown curve: got %v", err)
+	}
+	if _, err := decode(bezier[:5], func(dec *decoder) { dec.curve(&object{}) }); err != ErrUnexpectedEOF {
+		t.Errorf("truncated curve: got %v", err)
+	}
+}